@@ -0,0 +1,131 @@
+// Command import-postal-codes loads a GeoNames postal code export into the
+// postal_codes table used to place listings and searches on the map.
+//
+// The input is a tab-separated file in the GeoNames format, such as US.txt
+// from https://download.geonames.org/export/zip/. Postal codes are stored
+// without a country, so load a single country's export. Rows for a postal code
+// that is already present replace its coordinates, so the command can be
+// re-run against a newer export.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// GeoNames columns used by the importer.
+const (
+	columnPostalCode = 1
+	columnLatitude   = 9
+	columnLongitude  = 10
+)
+
+func main() {
+	err := run(context.Background(), os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("import-postal-codes", flag.ContinueOnError)
+	file := flags.String("file", "", "path to a GeoNames postal code export")
+	dbURL := flags.String("database-url", os.Getenv("DATABASE_URL"), "database connection string")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return fmt.Errorf("-file is required")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	pool, err := pgxpool.New(ctx, *dbURL)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	queries, tx, err := database.NewQueries(ctx, pool)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	count, err := importPostalCodes(ctx, queries, f)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	slog.Info("imported postal codes", "count", count)
+	return nil
+}
+
+// importPostalCodes upserts every row of r and returns how many it read.
+func importPostalCodes(ctx context.Context, queries *database.Queries, r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	count := 0
+
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) <= columnLongitude {
+			return count, fmt.Errorf("line %d: expected at least %d columns, got %d", line, columnLongitude+1, len(fields))
+		}
+
+		postalCode := database.NormalizePostalCode(fields[columnPostalCode])
+		if postalCode == "" {
+			continue
+		}
+
+		lat, err := strconv.ParseFloat(fields[columnLatitude], 64)
+		if err != nil {
+			return count, fmt.Errorf("line %d: invalid latitude: %w", line, err)
+		}
+
+		lng, err := strconv.ParseFloat(fields[columnLongitude], 64)
+		if err != nil {
+			return count, fmt.Errorf("line %d: invalid longitude: %w", line, err)
+		}
+
+		if !database.ValidCoordinates(lat, lng) {
+			return count, fmt.Errorf("line %d: coordinates out of range", line)
+		}
+
+		err = queries.UpsertPostalCode(ctx, database.UpsertPostalCodeParams{
+			PostalCode: postalCode,
+			Latitude:   lat,
+			Longitude:  lng,
+		})
+		if err != nil {
+			return count, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		count++
+	}
+
+	return count, scanner.Err()
+}
@@ -9,9 +9,9 @@ import (
 	"os"
 	"os/signal"
 
+	"github.com/DillonEnge/jolt/database"
 	"github.com/DillonEnge/jolt/internal/api"
 	server "github.com/DillonEnge/jolt/internal/service"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nats-io/nats.go"
 )
 
@@ -34,7 +34,7 @@ func run(ctx context.Context, _ []string, wait chan os.Signal) error {
 
 	config := api.NewConfig()
 
-	dbPool, err := pgxpool.New(ctx, config.DBUrl)
+	dbPool, err := database.NewPool(ctx, config.DBUrl)
 	if err != nil {
 		return err
 	}
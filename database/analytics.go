@@ -0,0 +1,81 @@
+package database
+
+import (
+	"slices"
+	"time"
+)
+
+// SellerAnalyticsSummary aggregates per-listing stats across a seller's
+// listings.
+type SellerAnalyticsSummary struct {
+	Listings     int
+	Sold         int
+	Views        int
+	Negotiations int
+	Offers       int
+	Messages     int
+	// MedianOfferRatio is the median, across listings that received offers,
+	// of each listing's median offer divided by its asking price. Zero when
+	// no offers have been made.
+	MedianOfferRatio float64
+	// AverageTimeToSale is the mean time from listing to sale over sold
+	// listings.
+	AverageTimeToSale time.Duration
+}
+
+// TimeToSale returns how long a sold listing took to sell.
+func TimeToSale(row SellerListingStatsRow) (time.Duration, bool) {
+	if !row.SoldAt.Valid || !row.CreatedAt.Valid {
+		return 0, false
+	}
+
+	return row.SoldAt.Time.Sub(row.CreatedAt.Time), true
+}
+
+// OfferRatio returns a listing's median offer as a fraction of its asking
+// price.
+func OfferRatio(row SellerListingStatsRow) (float64, bool) {
+	if row.OfferCount == 0 || row.Price == 0 {
+		return 0, false
+	}
+
+	return row.MedianOffer / float64(row.Price), true
+}
+
+// SummarizeSellerListings rolls per-listing stats up into a summary.
+func SummarizeSellerListings(rows []SellerListingStatsRow) SellerAnalyticsSummary {
+	summary := SellerAnalyticsSummary{Listings: len(rows)}
+
+	ratios := []float64{}
+	var totalTimeToSale time.Duration
+	for _, row := range rows {
+		summary.Views += int(row.Views)
+		summary.Negotiations += int(row.NegotiationCount)
+		summary.Offers += int(row.OfferCount)
+		summary.Messages += int(row.MessageCount)
+
+		if ratio, ok := OfferRatio(row); ok {
+			ratios = append(ratios, ratio)
+		}
+
+		if d, ok := TimeToSale(row); ok {
+			summary.Sold++
+			totalTimeToSale += d
+		}
+	}
+
+	if len(ratios) > 0 {
+		slices.Sort(ratios)
+		mid := len(ratios) / 2
+		summary.MedianOfferRatio = ratios[mid]
+		if len(ratios)%2 == 0 {
+			summary.MedianOfferRatio = (ratios[mid-1] + ratios[mid]) / 2
+		}
+	}
+
+	if summary.Sold > 0 {
+		summary.AverageTimeToSale = totalTimeToSale / time.Duration(summary.Sold)
+	}
+
+	return summary
+}
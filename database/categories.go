@@ -0,0 +1,29 @@
+package database
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultCategoryID is the catch-all category seeded for listings created
+// before categories existed.
+const DefaultCategoryID = "other"
+
+// CategorySlug derives a URL-safe slug from a category name.
+func CategorySlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	AttributeKindText   = "text"
+	AttributeKindEnum   = "enum"
+	AttributeKindNumber = "number"
+)
+
+// AttributeKinds lists every kind a category attribute may declare.
+var AttributeKinds = []string{
+	AttributeKindText,
+	AttributeKindEnum,
+	AttributeKindNumber,
+}
+
+// IsAttributeKind reports whether kind is a known attribute kind.
+func IsAttributeKind(kind string) bool {
+	return slices.Contains(AttributeKinds, kind)
+}
+
+// AttributeName normalizes a label such as "Shoe Size" into the key it is
+// stored under on listings.
+func AttributeName(label string) string {
+	return strings.ReplaceAll(CategorySlug(label), "-", "_")
+}
+
+// ValidateListingAttributes checks submitted values against a category's
+// attribute schema and returns the subset to store on the listing. Values for
+// attributes outside the schema are dropped.
+func ValidateListingAttributes(schema []CategoryAttribute, values map[string]string) (map[string]string, error) {
+	attributes := map[string]string{}
+	for _, a := range schema {
+		value := strings.TrimSpace(values[a.Name])
+		if value == "" {
+			if a.Required {
+				return nil, fmt.Errorf("%s is required", a.Name)
+			}
+			continue
+		}
+
+		switch a.Kind {
+		case AttributeKindEnum:
+			if !slices.Contains(a.Options, value) {
+				return nil, fmt.Errorf("%s must be one of %s", a.Name, strings.Join(a.Options, ", "))
+			}
+		case AttributeKindNumber:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("%s must be a number", a.Name)
+			}
+		}
+
+		attributes[a.Name] = value
+	}
+
+	return attributes, nil
+}
@@ -22,19 +22,96 @@ func (q *Queries) ListingViewsByID(ctx context.Context, listingID string) (int32
 	return views, err
 }
 
+const lockListingViewer = `-- name: LockListingViewer :exec
+SELECT pg_advisory_xact_lock(hashtext($1::text || ':' || $2::text))
+`
+
+type LockListingViewerParams struct {
+	ListingID string `json:"listing_id"`
+	ViewerKey string `json:"viewer_key"`
+}
+
+// Serializes RecordListingViewEvent for one viewer of a listing until the
+// transaction ends, so concurrent views can't both pass the dedupe check.
+func (q *Queries) LockListingViewer(ctx context.Context, arg LockListingViewerParams) error {
+	_, err := q.db.Exec(ctx, lockListingViewer, arg.ListingID, arg.ViewerKey)
+	return err
+}
+
+const pruneListingViewEvents = `-- name: PruneListingViewEvents :execrows
+DELETE FROM listing_view_events
+WHERE id IN (
+    SELECT e.id
+    FROM listing_view_events e
+    WHERE e.viewed_at < CURRENT_DATE - $1::int
+    LIMIT $2::int
+)
+`
+
+type PruneListingViewEventsParams struct {
+	RetentionDays int32 `json:"retention_days"`
+	MaxCount      int32 `json:"max_count"`
+}
+
+// Deletes up to max_count view events recorded before the last
+// retention_days days.
+func (q *Queries) PruneListingViewEvents(ctx context.Context, arg PruneListingViewEventsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, pruneListingViewEvents, arg.RetentionDays, arg.MaxCount)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const recordListingViewEvent = `-- name: RecordListingViewEvent :execrows
+INSERT INTO listing_view_events(listing_id, viewer_key)
+SELECT $1::text, $2::text
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM listing_view_events e
+    WHERE e.listing_id = $1::text
+    AND e.viewer_key = $2::text
+    AND e.viewed_at > NOW() - make_interval(secs => $3::int)
+)
+`
+
+type RecordListingViewEventParams struct {
+	ListingID     string `json:"listing_id"`
+	ViewerKey     string `json:"viewer_key"`
+	DedupeSeconds int32  `json:"dedupe_seconds"`
+}
+
+func (q *Queries) RecordListingViewEvent(ctx context.Context, arg RecordListingViewEventParams) (int64, error) {
+	result, err := q.db.Exec(ctx, recordListingViewEvent, arg.ListingID, arg.ViewerKey, arg.DedupeSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const upsertListingViews = `-- name: UpsertListingViews :one
-INSERT INTO listing_views(listing_id) VALUES(
-    $1::text
+INSERT INTO listing_views(listing_id, views, trending_score, score_updated_at) VALUES(
+    $1::text,
+    1,
+    1,
+    NOW()
 )
 ON CONFLICT(listing_id)
 DO UPDATE SET
-views = listing_views.views+1
-RETURNING listing_id, views
+views = listing_views.views+1,
+trending_score = decayed_score(listing_views.trending_score, listing_views.score_updated_at)+1,
+score_updated_at = NOW()
+RETURNING listing_id, views, trending_score, score_updated_at
 `
 
 func (q *Queries) UpsertListingViews(ctx context.Context, listingID string) (ListingView, error) {
 	row := q.db.QueryRow(ctx, upsertListingViews, listingID)
 	var i ListingView
-	err := row.Scan(&i.ListingID, &i.Views)
+	err := row.Scan(
+		&i.ListingID,
+		&i.Views,
+		&i.TrendingScore,
+		&i.ScoreUpdatedAt,
+	)
 	return i, err
 }
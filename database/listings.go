@@ -0,0 +1,129 @@
+package database
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	ListingStatusDraft    = "draft"
+	ListingStatusActive   = "active"
+	ListingStatusReserved = "reserved"
+	ListingStatusSold     = "sold"
+	ListingStatusArchived = "archived"
+)
+
+// ListingStatuses lists every listing status in lifecycle order.
+var ListingStatuses = []string{
+	ListingStatusDraft,
+	ListingStatusActive,
+	ListingStatusReserved,
+	ListingStatusSold,
+	ListingStatusArchived,
+}
+
+var listingTransitions = map[string][]string{
+	ListingStatusDraft: {
+		ListingStatusActive,
+		ListingStatusArchived,
+	},
+	ListingStatusActive: {
+		ListingStatusDraft,
+		ListingStatusReserved,
+		ListingStatusSold,
+		ListingStatusArchived,
+	},
+	ListingStatusReserved: {
+		ListingStatusActive,
+		ListingStatusSold,
+		ListingStatusArchived,
+	},
+	ListingStatusSold: {
+		ListingStatusArchived,
+	},
+	ListingStatusArchived: {
+		ListingStatusActive,
+	},
+}
+
+// CanTransitionListing reports whether a listing may move from one status to
+// another.
+func CanTransitionListing(from, to string) bool {
+	for _, v := range listingTransitions[from] {
+		if v == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CanEditListing reports whether a listing in status may have its details
+// changed. Reserved listings are under negotiation, and sold and archived
+// ones are history.
+func CanEditListing(status string) bool {
+	return status == ListingStatusDraft || status == ListingStatusActive
+}
+
+// IsListingStatus reports whether status is a known listing status.
+func IsListingStatus(status string) bool {
+	for _, v := range ListingStatuses {
+		if v == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListingSearchQuery converts free-form search input into a tsquery for
+// SearchListings. Every term is required and the last one is prefix-matched so
+// results keep up while the user is still typing. It returns an empty string
+// when the input contains no searchable terms.
+func ListingSearchQuery(input string) string {
+	terms := SearchTerms(input)
+	if len(terms) == 0 {
+		return ""
+	}
+
+	terms[len(terms)-1] += ":*"
+
+	return strings.Join(terms, " & ")
+}
+
+// SearchTerms splits search input into lowercase alphanumeric terms.
+func SearchTerms(input string) []string {
+	return strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+const (
+	ListingSortRelevance  = "relevance"
+	ListingSortNewest     = "newest"
+	ListingSortPriceAsc   = "price_asc"
+	ListingSortPriceDesc  = "price_desc"
+	ListingSortMostViewed = "most_viewed"
+	ListingSortDistance   = "distance"
+)
+
+// ListingSorts lists every sort order accepted by SearchListings.
+var ListingSorts = []string{
+	ListingSortRelevance,
+	ListingSortNewest,
+	ListingSortPriceAsc,
+	ListingSortPriceDesc,
+	ListingSortMostViewed,
+	ListingSortDistance,
+}
+
+// IsListingSort reports whether sort is a known listing sort order.
+func IsListingSort(sort string) bool {
+	for _, v := range ListingSorts {
+		if v == sort {
+			return true
+		}
+	}
+
+	return false
+}
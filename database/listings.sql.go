@@ -7,12 +7,14 @@ package database
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const deleteListing = `-- name: DeleteListing :one
 DELETE FROM listings l
 WHERE l.id = $1::text
-RETURNING id, name, description, price, seller_email
+RETURNING id, name, description, price, seller_email, status, reserved_negotiation_id, sold_negotiation_id, created_at, category_id, attributes, postal_code, latitude, longitude, sold_at, published_at
 `
 
 func (q *Queries) DeleteListing(ctx context.Context, listingID string) (Listing, error) {
@@ -24,12 +26,47 @@ func (q *Queries) DeleteListing(ctx context.Context, listingID string) (Listing,
 		&i.Description,
 		&i.Price,
 		&i.SellerEmail,
+		&i.Status,
+		&i.ReservedNegotiationID,
+		&i.SoldNegotiationID,
+		&i.CreatedAt,
+		&i.CategoryID,
+		&i.Attributes,
+		&i.PostalCode,
+		&i.Latitude,
+		&i.Longitude,
+		&i.SoldAt,
+		&i.PublishedAt,
 	)
 	return i, err
 }
 
+const deleteListingImages = `-- name: DeleteListingImages :exec
+WITH deleted AS (
+    DELETE FROM listing_images
+    WHERE listing_id = $1::text
+    AND id = ANY($2::text[])
+    RETURNING image_url, thumbnail_url, card_url
+)
+INSERT INTO pending_uploads(url)
+SELECT u.url
+FROM deleted d, unnest(ARRAY[d.image_url, d.thumbnail_url, d.card_url]) AS u(url)
+WHERE u.url IS NOT NULL
+ON CONFLICT DO NOTHING
+`
+
+type DeleteListingImagesParams struct {
+	ListingID    string   `json:"listing_id"`
+	ImageIDArray []string `json:"image_id_array"`
+}
+
+func (q *Queries) DeleteListingImages(ctx context.Context, arg DeleteListingImagesParams) error {
+	_, err := q.db.Exec(ctx, deleteListingImages, arg.ListingID, arg.ImageIDArray)
+	return err
+}
+
 const listingByID = `-- name: ListingByID :one
-SELECT l.id, l.name, l.description, l.price, l.seller_email, l.image_urls
+SELECT l.id, l.name, l.description, l.price, l.seller_email, l.status, l.reserved_negotiation_id, l.sold_negotiation_id, l.created_at, l.category_id, l.attributes, l.postal_code, l.latitude, l.longitude, l.sold_at, l.published_at, l.image_urls, l.thumbnail_urls, l.card_urls, l.image_ids, l.image_widths
 FROM listing_with_image_urls l
 WHERE l.id = $1::text
 `
@@ -43,19 +80,75 @@ func (q *Queries) ListingByID(ctx context.Context, listingID string) (ListingWit
 		&i.Description,
 		&i.Price,
 		&i.SellerEmail,
+		&i.Status,
+		&i.ReservedNegotiationID,
+		&i.SoldNegotiationID,
+		&i.CreatedAt,
+		&i.CategoryID,
+		&i.Attributes,
+		&i.PostalCode,
+		&i.Latitude,
+		&i.Longitude,
+		&i.SoldAt,
+		&i.PublishedAt,
 		&i.ImageUrls,
+		&i.ThumbnailUrls,
+		&i.CardUrls,
+		&i.ImageIds,
+		&i.ImageWidths,
 	)
 	return i, err
 }
 
-const listingsByLikeName = `-- name: ListingsByLikeName :many
-SELECT l.id, l.name, l.description, l.price, l.seller_email, l.image_urls
+const listingImageByID = `-- name: ListingImageByID :one
+SELECT li.listing_id, li.image_url, li.thumbnail_url, li.card_url, li.id, li.position, li.width, li.size_bytes
+FROM listing_images li
+WHERE li.id = $1::text
+`
+
+func (q *Queries) ListingImageByID(ctx context.Context, imageID string) (ListingImage, error) {
+	row := q.db.QueryRow(ctx, listingImageByID, imageID)
+	var i ListingImage
+	err := row.Scan(
+		&i.ListingID,
+		&i.ImageUrl,
+		&i.ThumbnailUrl,
+		&i.CardUrl,
+		&i.ID,
+		&i.Position,
+		&i.Width,
+		&i.SizeBytes,
+	)
+	return i, err
+}
+
+const listingImagesSize = `-- name: ListingImagesSize :one
+SELECT COALESCE(SUM(li.size_bytes), 0)::bigint
+FROM listing_images li
+WHERE li.listing_id = $1::text
+`
+
+func (q *Queries) ListingImagesSize(ctx context.Context, listingID string) (int64, error) {
+	row := q.db.QueryRow(ctx, listingImagesSize, listingID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const listingsBySellerEmail = `-- name: ListingsBySellerEmail :many
+SELECT l.id, l.name, l.description, l.price, l.seller_email, l.status, l.reserved_negotiation_id, l.sold_negotiation_id, l.created_at, l.category_id, l.attributes, l.postal_code, l.latitude, l.longitude, l.sold_at, l.published_at, l.image_urls, l.thumbnail_urls, l.card_urls, l.image_ids, l.image_widths
 FROM listing_with_image_urls l
-WHERE UPPER(l.name) LIKE UPPER('%' || $1::text || '%')
+WHERE UPPER(l.seller_email) = UPPER($1::text)
+AND ($2::text = '' OR l.status = $2::text)
 `
 
-func (q *Queries) ListingsByLikeName(ctx context.Context, listingName string) ([]ListingWithImageUrl, error) {
-	rows, err := q.db.Query(ctx, listingsByLikeName, listingName)
+type ListingsBySellerEmailParams struct {
+	SellerEmail string `json:"seller_email"`
+	Status      string `json:"status"`
+}
+
+func (q *Queries) ListingsBySellerEmail(ctx context.Context, arg ListingsBySellerEmailParams) ([]ListingWithImageUrl, error) {
+	rows, err := q.db.Query(ctx, listingsBySellerEmail, arg.SellerEmail, arg.Status)
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +162,22 @@ func (q *Queries) ListingsByLikeName(ctx context.Context, listingName string) ([
 			&i.Description,
 			&i.Price,
 			&i.SellerEmail,
+			&i.Status,
+			&i.ReservedNegotiationID,
+			&i.SoldNegotiationID,
+			&i.CreatedAt,
+			&i.CategoryID,
+			&i.Attributes,
+			&i.PostalCode,
+			&i.Latitude,
+			&i.Longitude,
+			&i.SoldAt,
+			&i.PublishedAt,
 			&i.ImageUrls,
+			&i.ThumbnailUrls,
+			&i.CardUrls,
+			&i.ImageIds,
+			&i.ImageWidths,
 		); err != nil {
 			return nil, err
 		}
@@ -81,14 +189,270 @@ func (q *Queries) ListingsByLikeName(ctx context.Context, listingName string) ([
 	return items, nil
 }
 
-const listingsBySellerEmail = `-- name: ListingsBySellerEmail :many
-SELECT l.id, l.name, l.description, l.price, l.seller_email, l.image_urls
+const recordListing = `-- name: RecordListing :one
+INSERT INTO listings(id, seller_email, name, description, price, status, category_id, attributes, postal_code, latitude, longitude, published_at) VALUES(
+    $1::text,
+    $2::text,
+    $3::text,
+    $4::text,
+    $5::int,
+    $6::text,
+    $7::text,
+    $8::jsonb,
+    $9::text,
+    $10::float8,
+    $11::float8,
+    CASE WHEN $6::text = 'active' THEN NOW() END
+)
+RETURNING id, name, description, price, seller_email, status, reserved_negotiation_id, sold_negotiation_id, created_at, category_id, attributes, postal_code, latitude, longitude, sold_at, published_at
+`
+
+type RecordListingParams struct {
+	ID          string        `json:"id"`
+	SellerEmail string        `json:"seller_email"`
+	ListingName string        `json:"listing_name"`
+	Description string        `json:"description"`
+	Price       int32         `json:"price"`
+	Status      string        `json:"status"`
+	CategoryID  string        `json:"category_id"`
+	Attributes  []byte        `json:"attributes"`
+	PostalCode  pgtype.Text   `json:"postal_code"`
+	Latitude    pgtype.Float8 `json:"latitude"`
+	Longitude   pgtype.Float8 `json:"longitude"`
+}
+
+func (q *Queries) RecordListing(ctx context.Context, arg RecordListingParams) (Listing, error) {
+	row := q.db.QueryRow(ctx, recordListing,
+		arg.ID,
+		arg.SellerEmail,
+		arg.ListingName,
+		arg.Description,
+		arg.Price,
+		arg.Status,
+		arg.CategoryID,
+		arg.Attributes,
+		arg.PostalCode,
+		arg.Latitude,
+		arg.Longitude,
+	)
+	var i Listing
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Price,
+		&i.SellerEmail,
+		&i.Status,
+		&i.ReservedNegotiationID,
+		&i.SoldNegotiationID,
+		&i.CreatedAt,
+		&i.CategoryID,
+		&i.Attributes,
+		&i.PostalCode,
+		&i.Latitude,
+		&i.Longitude,
+		&i.SoldAt,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const recordListingImages = `-- name: RecordListingImages :many
+INSERT INTO listing_images(listing_id, image_url, thumbnail_url, card_url, width, size_bytes, position)
+SELECT $1::text, u.image_url, u.thumbnail_url, u.card_url, u.width, u.size_bytes,
+    (SELECT COALESCE(MAX(li.position) + 1, 0) FROM listing_images li WHERE li.listing_id = $1::text) + u.n - 1
+FROM unnest($2::text[], $3::text[], $4::text[], $5::int[], $6::bigint[]) WITH ORDINALITY AS u(image_url, thumbnail_url, card_url, width, size_bytes, n)
+RETURNING listing_id, image_url, thumbnail_url, card_url, id, position, width, size_bytes
+`
+
+type RecordListingImagesParams struct {
+	ListingID         string   `json:"listing_id"`
+	ImageUrlArray     []string `json:"image_url_array"`
+	ThumbnailUrlArray []string `json:"thumbnail_url_array"`
+	CardUrlArray      []string `json:"card_url_array"`
+	WidthArray        []int32  `json:"width_array"`
+	SizeArray         []int64  `json:"size_array"`
+}
+
+func (q *Queries) RecordListingImages(ctx context.Context, arg RecordListingImagesParams) ([]ListingImage, error) {
+	rows, err := q.db.Query(ctx, recordListingImages,
+		arg.ListingID,
+		arg.ImageUrlArray,
+		arg.ThumbnailUrlArray,
+		arg.CardUrlArray,
+		arg.WidthArray,
+		arg.SizeArray,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListingImage
+	for rows.Next() {
+		var i ListingImage
+		if err := rows.Scan(
+			&i.ListingID,
+			&i.ImageUrl,
+			&i.ThumbnailUrl,
+			&i.CardUrl,
+			&i.ID,
+			&i.Position,
+			&i.Width,
+			&i.SizeBytes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const refreshSearchTerms = `-- name: RefreshSearchTerms :exec
+REFRESH MATERIALIZED VIEW CONCURRENTLY search_terms
+`
+
+func (q *Queries) RefreshSearchTerms(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, refreshSearchTerms)
+	return err
+}
+
+const reorderListingImages = `-- name: ReorderListingImages :exec
+UPDATE listing_images li
+SET position = o.n - 1
+FROM unnest($1::text[]) WITH ORDINALITY AS o(id, n)
+WHERE li.listing_id = $2::text
+AND li.id = o.id
+`
+
+type ReorderListingImagesParams struct {
+	ImageIDArray []string `json:"image_id_array"`
+	ListingID    string   `json:"listing_id"`
+}
+
+func (q *Queries) ReorderListingImages(ctx context.Context, arg ReorderListingImagesParams) error {
+	_, err := q.db.Exec(ctx, reorderListingImages, arg.ImageIDArray, arg.ListingID)
+	return err
+}
+
+const replaceListingImageVariants = `-- name: ReplaceListingImageVariants :execrows
+UPDATE listing_images
+SET image_url = $1::text,
+    thumbnail_url = $2::text,
+    card_url = $3::text,
+    width = $4::int,
+    size_bytes = $5::bigint
+WHERE id = $6::text
+AND image_url = $7::text
+`
+
+type ReplaceListingImageVariantsParams struct {
+	ImageUrl     string `json:"image_url"`
+	ThumbnailUrl string `json:"thumbnail_url"`
+	CardUrl      string `json:"card_url"`
+	Width        int32  `json:"width"`
+	SizeBytes    int64  `json:"size_bytes"`
+	ImageID      string `json:"image_id"`
+	OldImageUrl  string `json:"old_image_url"`
+}
+
+// Matches nothing if the image was deleted or replaced in the meantime.
+func (q *Queries) ReplaceListingImageVariants(ctx context.Context, arg ReplaceListingImageVariantsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, replaceListingImageVariants,
+		arg.ImageUrl,
+		arg.ThumbnailUrl,
+		arg.CardUrl,
+		arg.Width,
+		arg.SizeBytes,
+		arg.ImageID,
+		arg.OldImageUrl,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const searchListings = `-- name: SearchListings :many
+SELECT l.id, l.name, l.description, l.price, l.seller_email, l.status, l.reserved_negotiation_id, l.sold_negotiation_id, l.created_at, l.category_id, l.attributes, l.postal_code, l.latitude, l.longitude, l.sold_at, l.published_at, l.image_urls, l.thumbnail_urls, l.card_urls, l.image_ids, l.image_widths
 FROM listing_with_image_urls l
-WHERE UPPER(l.seller_email) = UPPER($1::text)
+LEFT JOIN (
+    SELECT ls.id, ts_rank(listing_search_vector(ls.name, ls.description), to_tsquery('english', $1::text)) AS rank
+    FROM listings ls
+    WHERE $1::text <> ''
+    AND listing_search_vector(ls.name, ls.description) @@ to_tsquery('english', $1::text)
+    UNION ALL
+    SELECT ls.id, word_similarity($2::text, ls.name) AS rank
+    FROM listings ls
+    WHERE $2::text <> ''
+    AND $2::text <% ls.name
+) matches ON matches.id = l.id
+LEFT JOIN listing_views lv ON lv.listing_id = l.id
+WHERE l.status = 'active'
+AND (($1::text = '' AND $2::text = '') OR matches.id IS NOT NULL)
+AND ($3::int IS NULL OR l.price >= $3::int)
+AND ($4::int IS NULL OR l.price <= $4::int)
+AND (NOT $5::boolean OR cardinality(l.image_urls) > 0)
+AND ($6::text = '' OR UPPER(l.seller_email) = UPPER($6::text))
+AND ($7::text IS NULL OR l.category_id IN (SELECT category_tree($7::text)))
+AND ($8::jsonb IS NULL OR l.attributes @> $8::jsonb)
+AND ($9::float8 IS NULL OR distance_km($10::float8, $11::float8, l.latitude, l.longitude) <= $9::float8)
+AND ($9::float8 IS NULL OR l.latitude BETWEEN $10::float8 - $9::float8 / 111.19 AND $10::float8 + $9::float8 / 111.19)
+AND ($12::timestamp IS NULL OR l.published_at > $12::timestamp)
+ORDER BY
+    CASE WHEN $13::text = 'price_asc' THEN l.price END ASC,
+    CASE WHEN $13::text = 'price_desc' THEN l.price END DESC,
+    CASE WHEN $13::text = 'newest' THEN l.created_at END DESC,
+    CASE WHEN $13::text = 'most_viewed' THEN COALESCE(lv.views, 0) END DESC,
+    CASE WHEN $13::text = 'distance' THEN distance_km($10::float8, $11::float8, l.latitude, l.longitude) END ASC NULLS LAST,
+    matches.rank DESC NULLS LAST,
+    l.created_at DESC
+LIMIT $14::int
+OFFSET $15::int
 `
 
-func (q *Queries) ListingsBySellerEmail(ctx context.Context, sellerEmail string) ([]ListingWithImageUrl, error) {
-	rows, err := q.db.Query(ctx, listingsBySellerEmail, sellerEmail)
+type SearchListingsParams struct {
+	SearchQuery     string           `json:"search_query"`
+	SimilarName     string           `json:"similar_name"`
+	MinPrice        pgtype.Int4      `json:"min_price"`
+	MaxPrice        pgtype.Int4      `json:"max_price"`
+	HasPhotos       bool             `json:"has_photos"`
+	SellerEmail     string           `json:"seller_email"`
+	CategoryID      pgtype.Text      `json:"category_id"`
+	Attributes      []byte           `json:"attributes"`
+	RadiusKm        pgtype.Float8    `json:"radius_km"`
+	OriginLatitude  pgtype.Float8    `json:"origin_latitude"`
+	OriginLongitude pgtype.Float8    `json:"origin_longitude"`
+	PublishedAfter  pgtype.Timestamp `json:"published_after"`
+	Sort            string           `json:"sort"`
+	PageSize        int32            `json:"page_size"`
+	PageOffset      int32            `json:"page_offset"`
+}
+
+// Radius searches are also limited to a band of latitudes, which
+// listings_location_idx can serve, before distance_km is computed per row. A
+// degree of latitude spans about 111.19 km, so the band never excludes a
+// listing within the radius.
+func (q *Queries) SearchListings(ctx context.Context, arg SearchListingsParams) ([]ListingWithImageUrl, error) {
+	rows, err := q.db.Query(ctx, searchListings,
+		arg.SearchQuery,
+		arg.SimilarName,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.HasPhotos,
+		arg.SellerEmail,
+		arg.CategoryID,
+		arg.Attributes,
+		arg.RadiusKm,
+		arg.OriginLatitude,
+		arg.OriginLongitude,
+		arg.PublishedAfter,
+		arg.Sort,
+		arg.PageSize,
+		arg.PageOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -102,7 +466,22 @@ func (q *Queries) ListingsBySellerEmail(ctx context.Context, sellerEmail string)
 			&i.Description,
 			&i.Price,
 			&i.SellerEmail,
+			&i.Status,
+			&i.ReservedNegotiationID,
+			&i.SoldNegotiationID,
+			&i.CreatedAt,
+			&i.CategoryID,
+			&i.Attributes,
+			&i.PostalCode,
+			&i.Latitude,
+			&i.Longitude,
+			&i.SoldAt,
+			&i.PublishedAt,
 			&i.ImageUrls,
+			&i.ThumbnailUrls,
+			&i.CardUrls,
+			&i.ImageIds,
+			&i.ImageWidths,
 		); err != nil {
 			return nil, err
 		}
@@ -114,22 +493,56 @@ func (q *Queries) ListingsBySellerEmail(ctx context.Context, sellerEmail string)
 	return items, nil
 }
 
-const listingsByViews = `-- name: ListingsByViews :many
-SELECT l.id, l.name, l.description, l.price, l.seller_email, l.image_urls
+const suggestSearchTerm = `-- name: SuggestSearchTerm :one
+SELECT v.word::text
+FROM search_terms v
+WHERE v.word % $1::text
+AND levenshtein(v.word, $1::text) <= 2
+ORDER BY levenshtein(v.word, $1::text) ASC, v.ndoc DESC
+LIMIT 1
+`
+
+// Candidates come from the trigram index, so a misspelling that shares few
+// trigrams with the word, as in very short words, goes unsuggested.
+func (q *Queries) SuggestSearchTerm(ctx context.Context, term string) (string, error) {
+	row := q.db.QueryRow(ctx, suggestSearchTerm, term)
+	var word string
+	err := row.Scan(&word)
+	return word, err
+}
+
+const trendingListings = `-- name: TrendingListings :many
+SELECT l.id, l.name, l.description, l.price, l.seller_email, l.status, l.reserved_negotiation_id, l.sold_negotiation_id, l.created_at, l.category_id, l.attributes, l.postal_code, l.latitude, l.longitude, l.sold_at, l.published_at, l.image_urls, l.thumbnail_urls, l.card_urls, l.image_ids, l.image_widths
 FROM listing_with_image_urls l
-JOIN listing_views lv ON lv.listing_id = l.id
-ORDER BY lv.views DESC
-LIMIT $1
-OFFSET $2
+LEFT JOIN listing_views lv ON lv.listing_id = l.id
+WHERE l.status = 'active'
+AND ($1::text IS NULL OR l.category_id IN (SELECT category_tree($1::text)))
+ORDER BY
+    CASE WHEN $2::text = 'distance' THEN distance_km($3::float8, $4::float8, l.latitude, l.longitude) END ASC NULLS LAST,
+    COALESCE(decayed_score(lv.trending_score, lv.score_updated_at), 0) DESC,
+    l.created_at DESC
+LIMIT $5::int
+OFFSET $6::int
 `
 
-type ListingsByViewsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+type TrendingListingsParams struct {
+	CategoryID      pgtype.Text   `json:"category_id"`
+	Sort            string        `json:"sort"`
+	OriginLatitude  pgtype.Float8 `json:"origin_latitude"`
+	OriginLongitude pgtype.Float8 `json:"origin_longitude"`
+	PageSize        int32         `json:"page_size"`
+	PageOffset      int32         `json:"page_offset"`
 }
 
-func (q *Queries) ListingsByViews(ctx context.Context, arg ListingsByViewsParams) ([]ListingWithImageUrl, error) {
-	rows, err := q.db.Query(ctx, listingsByViews, arg.Limit, arg.Offset)
+func (q *Queries) TrendingListings(ctx context.Context, arg TrendingListingsParams) ([]ListingWithImageUrl, error) {
+	rows, err := q.db.Query(ctx, trendingListings,
+		arg.CategoryID,
+		arg.Sort,
+		arg.OriginLatitude,
+		arg.OriginLongitude,
+		arg.PageSize,
+		arg.PageOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -143,7 +556,22 @@ func (q *Queries) ListingsByViews(ctx context.Context, arg ListingsByViewsParams
 			&i.Description,
 			&i.Price,
 			&i.SellerEmail,
+			&i.Status,
+			&i.ReservedNegotiationID,
+			&i.SoldNegotiationID,
+			&i.CreatedAt,
+			&i.CategoryID,
+			&i.Attributes,
+			&i.PostalCode,
+			&i.Latitude,
+			&i.Longitude,
+			&i.SoldAt,
+			&i.PublishedAt,
 			&i.ImageUrls,
+			&i.ThumbnailUrls,
+			&i.CardUrls,
+			&i.ImageIds,
+			&i.ImageWidths,
 		); err != nil {
 			return nil, err
 		}
@@ -155,32 +583,73 @@ func (q *Queries) ListingsByViews(ctx context.Context, arg ListingsByViewsParams
 	return items, nil
 }
 
-const recordListing = `-- name: RecordListing :one
-INSERT INTO listings(id, seller_email, name, description, price) VALUES(
-    $1::text,
-    $2::text,
-    $3::text,
-    $4::text,
-    $5::int
-)
-RETURNING id, name, description, price, seller_email
+const unsizedListingImages = `-- name: UnsizedListingImages :many
+SELECT li.listing_id, li.image_url, li.thumbnail_url, li.card_url, li.id, li.position, li.width, li.size_bytes
+FROM listing_images li
+WHERE (li.width IS NULL OR li.size_bytes IS NULL)
+AND li.id > $1::text
+ORDER BY li.id
+LIMIT $2::int
 `
 
-type RecordListingParams struct {
-	ID          string `json:"id"`
-	SellerEmail string `json:"seller_email"`
+type UnsizedListingImagesParams struct {
+	AfterID  string `json:"after_id"`
+	MaxCount int32  `json:"max_count"`
+}
+
+// Images stored before their width or size was recorded, including every
+// image stored before variants, in ID order after after_id.
+func (q *Queries) UnsizedListingImages(ctx context.Context, arg UnsizedListingImagesParams) ([]ListingImage, error) {
+	rows, err := q.db.Query(ctx, unsizedListingImages, arg.AfterID, arg.MaxCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListingImage
+	for rows.Next() {
+		var i ListingImage
+		if err := rows.Scan(
+			&i.ListingID,
+			&i.ImageUrl,
+			&i.ThumbnailUrl,
+			&i.CardUrl,
+			&i.ID,
+			&i.Position,
+			&i.Width,
+			&i.SizeBytes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateListing = `-- name: UpdateListing :one
+UPDATE listings
+SET name = $1::text,
+    description = $2::text,
+    price = $3::int
+WHERE id = $4::text
+RETURNING id, name, description, price, seller_email, status, reserved_negotiation_id, sold_negotiation_id, created_at, category_id, attributes, postal_code, latitude, longitude, sold_at, published_at
+`
+
+type UpdateListingParams struct {
 	ListingName string `json:"listing_name"`
 	Description string `json:"description"`
 	Price       int32  `json:"price"`
+	ListingID   string `json:"listing_id"`
 }
 
-func (q *Queries) RecordListing(ctx context.Context, arg RecordListingParams) (Listing, error) {
-	row := q.db.QueryRow(ctx, recordListing,
-		arg.ID,
-		arg.SellerEmail,
+func (q *Queries) UpdateListing(ctx context.Context, arg UpdateListingParams) (Listing, error) {
+	row := q.db.QueryRow(ctx, updateListing,
 		arg.ListingName,
 		arg.Description,
 		arg.Price,
+		arg.ListingID,
 	)
 	var i Listing
 	err := row.Scan(
@@ -189,39 +658,84 @@ func (q *Queries) RecordListing(ctx context.Context, arg RecordListingParams) (L
 		&i.Description,
 		&i.Price,
 		&i.SellerEmail,
+		&i.Status,
+		&i.ReservedNegotiationID,
+		&i.SoldNegotiationID,
+		&i.CreatedAt,
+		&i.CategoryID,
+		&i.Attributes,
+		&i.PostalCode,
+		&i.Latitude,
+		&i.Longitude,
+		&i.SoldAt,
+		&i.PublishedAt,
 	)
 	return i, err
 }
 
-const recordListingImages = `-- name: RecordListingImages :many
-INSERT INTO listing_images(listing_id, image_url) VALUES(
-    $1::text,
-    unnest($2::text[])
-)
-RETURNING listing_id, image_url
+const updateListingStatus = `-- name: UpdateListingStatus :one
+UPDATE listings
+SET status = $1::text,
+    reserved_negotiation_id = CASE
+        WHEN $1::text = 'active' THEN NULL
+        ELSE COALESCE($2::text, reserved_negotiation_id)
+    END,
+    sold_negotiation_id = CASE
+        WHEN status = 'archived' AND $1::text = 'active' THEN NULL
+        ELSE COALESCE($3::text, sold_negotiation_id)
+    END,
+    sold_at = CASE
+        WHEN $1::text = 'sold' THEN NOW()
+        WHEN status = 'archived' AND $1::text = 'active' THEN NULL
+        ELSE sold_at
+    END,
+    published_at = CASE
+        WHEN $1::text = 'active' THEN COALESCE(published_at, NOW())
+        ELSE published_at
+    END
+WHERE id = $4::text
+AND status = $5::text
+RETURNING id, name, description, price, seller_email, status, reserved_negotiation_id, sold_negotiation_id, created_at, category_id, attributes, postal_code, latitude, longitude, sold_at, published_at
 `
 
-type RecordListingImagesParams struct {
-	ListingID     string   `json:"listing_id"`
-	ImageUrlArray []string `json:"image_url_array"`
+type UpdateListingStatusParams struct {
+	Status                string      `json:"status"`
+	ReservedNegotiationID pgtype.Text `json:"reserved_negotiation_id"`
+	SoldNegotiationID     pgtype.Text `json:"sold_negotiation_id"`
+	ListingID             string      `json:"listing_id"`
+	FromStatus            string      `json:"from_status"`
 }
 
-func (q *Queries) RecordListingImages(ctx context.Context, arg RecordListingImagesParams) ([]ListingImage, error) {
-	rows, err := q.db.Query(ctx, recordListingImages, arg.ListingID, arg.ImageUrlArray)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []ListingImage
-	for rows.Next() {
-		var i ListingImage
-		if err := rows.Scan(&i.ListingID, &i.ImageUrl); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+// Each transition writes only the columns it sets. A released reservation
+// clears its negotiation, while a sale's negotiation and time survive
+// archiving and are cleared only when an archived listing is relisted. The
+// first activation stamps published_at.
+func (q *Queries) UpdateListingStatus(ctx context.Context, arg UpdateListingStatusParams) (Listing, error) {
+	row := q.db.QueryRow(ctx, updateListingStatus,
+		arg.Status,
+		arg.ReservedNegotiationID,
+		arg.SoldNegotiationID,
+		arg.ListingID,
+		arg.FromStatus,
+	)
+	var i Listing
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Price,
+		&i.SellerEmail,
+		&i.Status,
+		&i.ReservedNegotiationID,
+		&i.SoldNegotiationID,
+		&i.CreatedAt,
+		&i.CategoryID,
+		&i.Attributes,
+		&i.PostalCode,
+		&i.Latitude,
+		&i.Longitude,
+		&i.SoldAt,
+		&i.PublishedAt,
+	)
+	return i, err
 }
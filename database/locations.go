@@ -0,0 +1,28 @@
+package database
+
+import (
+	"math"
+	"strings"
+)
+
+// coordinatePrecision is the number of decimal places kept on stored
+// coordinates. Two places is roughly a kilometre, enough to sort by distance
+// without pinpointing a seller's address.
+const coordinatePrecision = 2
+
+// RoundCoordinate coarsens a latitude or longitude for storage.
+func RoundCoordinate(v float64) float64 {
+	scale := math.Pow(10, coordinatePrecision)
+
+	return math.Round(v*scale) / scale
+}
+
+// ValidCoordinates reports whether lat and lng fall within their ranges.
+func ValidCoordinates(lat float64, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// NormalizePostalCode canonicalizes a postal code for lookup.
+func NormalizePostalCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,12 @@
+CREATE TABLE offers(
+    id varchar(255),
+    negotiation_id varchar(255) NOT NULL REFERENCES negotiations(id) ON DELETE CASCADE,
+    sender_email varchar(255) NOT NULL,
+    kind varchar(255) NOT NULL,
+    amount int NOT NULL,
+    status varchar(255) NOT NULL DEFAULT 'pending',
+    time_sent TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id)
+);
+---- create above / drop below ----
+DROP TABLE offers;
@@ -0,0 +1,5 @@
+ALTER TABLE negotiations
+ADD COLUMN status varchar(255) NOT NULL DEFAULT 'open';
+---- create above / drop below ----
+ALTER TABLE negotiations
+DROP COLUMN status;
@@ -0,0 +1,24 @@
+ALTER TABLE listings
+ADD COLUMN status varchar(255) NOT NULL DEFAULT 'active',
+ADD COLUMN reserved_negotiation_id varchar(255) REFERENCES negotiations(id) ON DELETE SET NULL,
+ADD COLUMN sold_negotiation_id varchar(255) REFERENCES negotiations(id) ON DELETE SET NULL;
+
+DROP VIEW listing_with_image_urls;
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*, COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
+---- create above / drop below ----
+DROP VIEW listing_with_image_urls;
+
+ALTER TABLE listings
+DROP COLUMN status,
+DROP COLUMN reserved_negotiation_id,
+DROP COLUMN sold_negotiation_id;
+
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*, COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
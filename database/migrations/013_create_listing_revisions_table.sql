@@ -0,0 +1,18 @@
+CREATE TABLE listing_revisions(
+    id varchar(255),
+    listing_id varchar(255) NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+    name varchar(255) NOT NULL,
+    description varchar(255),
+    price int NOT NULL,
+    image_urls text[] NOT NULL,
+    revised_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id)
+);
+
+ALTER TABLE negotiations
+ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT NOW();
+---- create above / drop below ----
+ALTER TABLE negotiations
+DROP COLUMN created_at;
+
+DROP TABLE listing_revisions;
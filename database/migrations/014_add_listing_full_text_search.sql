@@ -0,0 +1,10 @@
+CREATE FUNCTION listing_search_vector(name text, description text) RETURNS tsvector
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+$$;
+
+CREATE INDEX listings_search_idx ON listings USING GIN (listing_search_vector(name, description));
+---- create above / drop below ----
+DROP INDEX listings_search_idx;
+DROP FUNCTION listing_search_vector(text, text);
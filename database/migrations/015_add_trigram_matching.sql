@@ -0,0 +1,3 @@
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+---- create above / drop below ----
+DROP EXTENSION "pg_trgm";
@@ -0,0 +1,51 @@
+CREATE TABLE categories(
+    id varchar(255),
+    parent_id varchar(255) REFERENCES categories(id) ON DELETE RESTRICT,
+    name varchar(255) NOT NULL,
+    slug varchar(255) NOT NULL UNIQUE,
+    PRIMARY KEY(id)
+);
+
+-- category_tree returns the given category and every category beneath it.
+CREATE FUNCTION category_tree(root_id varchar) RETURNS TABLE(id varchar)
+LANGUAGE sql STABLE AS $$
+    WITH RECURSIVE tree AS (
+        SELECT c.id FROM categories c WHERE c.id = root_id
+        UNION ALL
+        SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+    )
+    SELECT tree.id FROM tree
+$$;
+
+INSERT INTO categories(id, parent_id, name, slug) VALUES('other', NULL, 'Other', 'other');
+
+ALTER TABLE listings
+ADD COLUMN category_id varchar(255) REFERENCES categories(id) ON DELETE RESTRICT;
+
+UPDATE listings SET category_id = 'other';
+
+ALTER TABLE listings
+ALTER COLUMN category_id SET NOT NULL;
+
+CREATE INDEX listings_category_id_idx ON listings(category_id);
+
+DROP VIEW listing_with_image_urls;
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*, COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
+---- create above / drop below ----
+DROP VIEW listing_with_image_urls;
+
+ALTER TABLE listings
+DROP COLUMN category_id;
+
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*, COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
+
+DROP FUNCTION category_tree;
+DROP TABLE categories;
@@ -0,0 +1,37 @@
+CREATE TABLE category_attributes(
+    id varchar(255),
+    category_id varchar(255) NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    name varchar(255) NOT NULL,
+    kind varchar(255) NOT NULL DEFAULT 'text',
+    options text[] NOT NULL DEFAULT ARRAY[]::text[],
+    required boolean NOT NULL DEFAULT false,
+    PRIMARY KEY(id),
+    UNIQUE(category_id, name)
+);
+
+ALTER TABLE listings
+ADD COLUMN attributes jsonb NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX listings_attributes_idx ON listings USING GIN (attributes jsonb_path_ops);
+
+DROP VIEW listing_with_image_urls;
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*, COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
+---- create above / drop below ----
+DROP VIEW listing_with_image_urls;
+
+DROP INDEX listings_attributes_idx;
+
+ALTER TABLE listings
+DROP COLUMN attributes;
+
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*, COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
+
+DROP TABLE category_attributes;
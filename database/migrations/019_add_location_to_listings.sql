@@ -0,0 +1,49 @@
+-- postal_codes maps postal codes to the approximate centre of the area they
+-- cover. It is loaded from an external gazetteer export.
+CREATE TABLE postal_codes(
+    postal_code varchar(32),
+    latitude double precision NOT NULL,
+    longitude double precision NOT NULL,
+    PRIMARY KEY(postal_code)
+);
+
+-- distance_km returns the great-circle distance between two points.
+CREATE FUNCTION distance_km(lat1 double precision, lon1 double precision, lat2 double precision, lon2 double precision) RETURNS double precision
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT 6371 * 2 * asin(sqrt(
+        power(sin(radians(lat2 - lat1) / 2), 2) +
+        cos(radians(lat1)) * cos(radians(lat2)) * power(sin(radians(lon2 - lon1) / 2), 2)
+    ))
+$$;
+
+ALTER TABLE listings
+ADD COLUMN postal_code varchar(32),
+ADD COLUMN latitude double precision,
+ADD COLUMN longitude double precision;
+
+CREATE INDEX listings_location_idx ON listings(latitude, longitude);
+
+DROP VIEW listing_with_image_urls;
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*, COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
+---- create above / drop below ----
+DROP VIEW listing_with_image_urls;
+
+DROP INDEX listings_location_idx;
+
+ALTER TABLE listings
+DROP COLUMN postal_code,
+DROP COLUMN latitude,
+DROP COLUMN longitude;
+
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*, COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
+
+DROP FUNCTION distance_km;
+DROP TABLE postal_codes;
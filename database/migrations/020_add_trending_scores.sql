@@ -0,0 +1,30 @@
+CREATE TABLE listing_view_events(
+    id bigint GENERATED ALWAYS AS IDENTITY,
+    listing_id varchar(255) NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+    viewed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX listing_view_events_listing_id_viewed_at_idx ON listing_view_events(listing_id, viewed_at);
+
+-- trending_score holds an exponentially decayed view count as of
+-- score_updated_at. Each view decays the stored score up to the present and
+-- adds one, so the score never needs a full recomputation.
+ALTER TABLE listing_views
+ADD COLUMN trending_score double precision NOT NULL DEFAULT 0,
+ADD COLUMN score_updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- decayed_score brings a stored trending score forward to the present using
+-- a one day half-life.
+CREATE FUNCTION decayed_score(score double precision, updated_at timestamp) RETURNS double precision
+LANGUAGE sql STABLE AS $$
+    SELECT score * power(0.5, EXTRACT(EPOCH FROM (NOW()::timestamp - updated_at)) / 86400.0)
+$$;
+---- create above / drop below ----
+DROP FUNCTION decayed_score;
+
+ALTER TABLE listing_views
+DROP COLUMN trending_score,
+DROP COLUMN score_updated_at;
+
+DROP TABLE listing_view_events;
@@ -0,0 +1,9 @@
+ALTER TABLE listing_view_events
+ADD COLUMN viewer_key varchar(255) NOT NULL DEFAULT '';
+
+CREATE INDEX listing_view_events_viewer_idx ON listing_view_events(listing_id, viewer_key, viewed_at);
+---- create above / drop below ----
+DROP INDEX listing_view_events_viewer_idx;
+
+ALTER TABLE listing_view_events
+DROP COLUMN viewer_key;
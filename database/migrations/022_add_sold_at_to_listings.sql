@@ -0,0 +1,30 @@
+ALTER TABLE listings
+ADD COLUMN sold_at TIMESTAMP;
+
+-- Approximate the sale time of listings sold before this column existed
+-- with the last message in the winning negotiation.
+UPDATE listings l
+SET sold_at = (
+    SELECT MAX(m.time_sent)
+    FROM messages m
+    WHERE m.negotiation_id = l.sold_negotiation_id
+)
+WHERE l.status = 'sold';
+
+DROP VIEW listing_with_image_urls;
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*, COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
+---- create above / drop below ----
+DROP VIEW listing_with_image_urls;
+
+ALTER TABLE listings
+DROP COLUMN sold_at;
+
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*, COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
@@ -0,0 +1,26 @@
+CREATE TABLE watchlist(
+    user_email varchar(255) NOT NULL,
+    listing_id varchar(255) NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(user_email, listing_id)
+);
+
+CREATE INDEX watchlist_listing_id_idx ON watchlist(listing_id);
+
+-- listing_id is deliberately not a foreign key so notifications about a
+-- deleted listing outlive it.
+CREATE TABLE notifications(
+    id varchar(255),
+    user_email varchar(255) NOT NULL,
+    listing_id varchar(255) NOT NULL,
+    kind varchar(255) NOT NULL,
+    message text NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMP,
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX notifications_user_email_created_at_idx ON notifications(user_email, created_at);
+---- create above / drop below ----
+DROP TABLE notifications;
+DROP TABLE watchlist;
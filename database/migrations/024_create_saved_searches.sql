@@ -0,0 +1,17 @@
+-- params holds the resolved SearchListings filters so alerts can re-run the
+-- search without a request; query is the original query string for the UI.
+CREATE TABLE saved_searches(
+    id varchar(255),
+    user_email varchar(255) NOT NULL,
+    name varchar(255) NOT NULL,
+    query text NOT NULL,
+    params jsonb NOT NULL,
+    frequency varchar(255) NOT NULL DEFAULT 'daily',
+    last_checked_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX saved_searches_user_email_idx ON saved_searches(user_email);
+---- create above / drop below ----
+DROP TABLE saved_searches;
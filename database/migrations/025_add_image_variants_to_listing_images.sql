@@ -0,0 +1,27 @@
+-- image_url holds the full-size rendition. Images uploaded before variants
+-- existed have neither column set and fall back to it in the view.
+ALTER TABLE listing_images
+ADD COLUMN thumbnail_url varchar(255),
+ADD COLUMN card_url varchar(255);
+
+DROP VIEW listing_with_image_urls;
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*,
+    COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls,
+    COALESCE(array_agg(COALESCE(li.thumbnail_url, li.image_url)) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS thumbnail_urls,
+    COALESCE(array_agg(COALESCE(li.card_url, li.image_url)) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS card_urls
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
+---- create above / drop below ----
+DROP VIEW listing_with_image_urls;
+
+ALTER TABLE listing_images
+DROP COLUMN thumbnail_url,
+DROP COLUMN card_url;
+
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*, COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
@@ -0,0 +1,13 @@
+-- pending_uploads tracks stored blobs that no listing is known to reference:
+-- uploads whose listing hasn't been committed yet, and images released by a
+-- deleted listing. Rows that outlive the grace period and are still
+-- unreferenced are deleted from storage by the reconciler.
+CREATE TABLE pending_uploads(
+    url varchar(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(url)
+);
+
+CREATE INDEX pending_uploads_created_at_idx ON pending_uploads(created_at);
+---- create above / drop below ----
+DROP TABLE pending_uploads;
@@ -0,0 +1,31 @@
+-- Images are served through Jolt by ID rather than straight from storage, so
+-- each needs a stable identifier independent of where its blobs live.
+ALTER TABLE listing_images
+ADD COLUMN id varchar(255) NOT NULL DEFAULT uuid_generate_v4()::text;
+
+CREATE UNIQUE INDEX listing_images_id_idx ON listing_images(id);
+
+DROP VIEW listing_with_image_urls;
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*,
+    COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls,
+    COALESCE(array_agg(COALESCE(li.thumbnail_url, li.image_url)) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS thumbnail_urls,
+    COALESCE(array_agg(COALESCE(li.card_url, li.image_url)) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS card_urls,
+    COALESCE(array_agg(li.id) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_ids
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
+---- create above / drop below ----
+DROP VIEW listing_with_image_urls;
+
+ALTER TABLE listing_images
+DROP COLUMN id;
+
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*,
+    COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls,
+    COALESCE(array_agg(COALESCE(li.thumbnail_url, li.image_url)) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS thumbnail_urls,
+    COALESCE(array_agg(COALESCE(li.card_url, li.image_url)) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS card_urls
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
@@ -0,0 +1,42 @@
+-- position orders a listing's images; the first is its cover. Existing
+-- images had no order, so they are numbered by URL.
+ALTER TABLE listing_images
+ADD COLUMN position int NOT NULL DEFAULT 0;
+
+UPDATE listing_images li
+SET position = ordered.position
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY listing_id ORDER BY image_url) - 1 AS position
+    FROM listing_images
+) ordered
+WHERE ordered.id = li.id;
+
+CREATE INDEX listing_images_listing_id_position_idx ON listing_images(listing_id, position);
+
+DROP VIEW listing_with_image_urls;
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*,
+    COALESCE(array_agg(li.image_url ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls,
+    COALESCE(array_agg(COALESCE(li.thumbnail_url, li.image_url) ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS thumbnail_urls,
+    COALESCE(array_agg(COALESCE(li.card_url, li.image_url) ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS card_urls,
+    COALESCE(array_agg(li.id ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_ids
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
+---- create above / drop below ----
+DROP VIEW listing_with_image_urls;
+
+DROP INDEX listing_images_listing_id_position_idx;
+
+ALTER TABLE listing_images
+DROP COLUMN position;
+
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*,
+    COALESCE(array_agg(li.image_url) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls,
+    COALESCE(array_agg(COALESCE(li.thumbnail_url, li.image_url)) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS thumbnail_urls,
+    COALESCE(array_agg(COALESCE(li.card_url, li.image_url)) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS card_urls,
+    COALESCE(array_agg(li.id) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_ids
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
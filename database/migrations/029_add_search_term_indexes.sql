@@ -0,0 +1,15 @@
+-- search_terms holds the words of active listings for did-you-mean
+-- suggestions, so a zero-result search no longer rebuilds every listing's
+-- tsvector. It is refreshed in the background.
+CREATE MATERIALIZED VIEW search_terms AS
+SELECT v.word, v.ndoc
+FROM ts_stat('SELECT to_tsvector(''simple'', l.name || '' '' || coalesce(l.description, '''')) FROM listings l WHERE l.status = ''active''') v;
+
+-- The unique index lets the view be refreshed concurrently.
+CREATE UNIQUE INDEX search_terms_word_idx ON search_terms(word);
+CREATE INDEX search_terms_word_trgm_idx ON search_terms USING GIN (word gin_trgm_ops);
+
+CREATE INDEX listings_name_trgm_idx ON listings USING GIN (name gin_trgm_ops);
+---- create above / drop below ----
+DROP INDEX listings_name_trgm_idx;
+DROP MATERIALIZED VIEW search_terms;
@@ -0,0 +1,53 @@
+-- published_at is when a listing first went live. Saved searches match on it
+-- rather than created_at, so a draft published later still reaches them.
+-- Listings that were already live count as published when created.
+ALTER TABLE listings
+ADD COLUMN published_at TIMESTAMP;
+
+UPDATE listings
+SET published_at = created_at
+WHERE status <> 'draft';
+
+CREATE INDEX listings_published_at_idx ON listings(published_at);
+
+-- The matcher looks back past its checkpoint to catch listings committed
+-- late, so a saved search match is recorded at most once per user and
+-- listing.
+DELETE FROM notifications n
+USING notifications d
+WHERE n.kind = 'saved_search'
+AND d.kind = 'saved_search'
+AND n.user_email = d.user_email
+AND n.listing_id = d.listing_id
+AND (n.created_at, n.id) > (d.created_at, d.id);
+
+CREATE UNIQUE INDEX notifications_saved_search_match_idx ON notifications(user_email, listing_id) WHERE kind = 'saved_search';
+
+DROP VIEW listing_with_image_urls;
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*,
+    COALESCE(array_agg(li.image_url ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls,
+    COALESCE(array_agg(COALESCE(li.thumbnail_url, li.image_url) ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS thumbnail_urls,
+    COALESCE(array_agg(COALESCE(li.card_url, li.image_url) ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS card_urls,
+    COALESCE(array_agg(li.id ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_ids
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
+---- create above / drop below ----
+DROP VIEW listing_with_image_urls;
+
+DROP INDEX notifications_saved_search_match_idx;
+DROP INDEX listings_published_at_idx;
+
+ALTER TABLE listings
+DROP COLUMN published_at;
+
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*,
+    COALESCE(array_agg(li.image_url ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls,
+    COALESCE(array_agg(COALESCE(li.thumbnail_url, li.image_url) ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS thumbnail_urls,
+    COALESCE(array_agg(COALESCE(li.card_url, li.image_url) ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS card_urls,
+    COALESCE(array_agg(li.id ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_ids
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
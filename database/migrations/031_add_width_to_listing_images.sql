@@ -0,0 +1,33 @@
+-- width is the pixel width of an image's full rendition. The smaller
+-- renditions are the same image capped at their own widths, so it is enough
+-- to describe every rendition in a srcset. Images stored before variants have
+-- no width until the backfill reprocesses them.
+ALTER TABLE listing_images
+ADD COLUMN width int;
+
+DROP VIEW listing_with_image_urls;
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*,
+    COALESCE(array_agg(li.image_url ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls,
+    COALESCE(array_agg(COALESCE(li.thumbnail_url, li.image_url) ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS thumbnail_urls,
+    COALESCE(array_agg(COALESCE(li.card_url, li.image_url) ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS card_urls,
+    COALESCE(array_agg(li.id ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_ids,
+    COALESCE(array_agg(COALESCE(li.width, 0) ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::int[])::int[] AS image_widths
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
+---- create above / drop below ----
+DROP VIEW listing_with_image_urls;
+
+ALTER TABLE listing_images
+DROP COLUMN width;
+
+CREATE VIEW listing_with_image_urls AS
+SELECT l.*,
+    COALESCE(array_agg(li.image_url ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_urls,
+    COALESCE(array_agg(COALESCE(li.thumbnail_url, li.image_url) ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS thumbnail_urls,
+    COALESCE(array_agg(COALESCE(li.card_url, li.image_url) ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS card_urls,
+    COALESCE(array_agg(li.id ORDER BY li.position) FILTER (WHERE li.image_url IS NOT NULL), ARRAY[]::text[])::text[] AS image_ids
+FROM listings l
+LEFT JOIN listing_images li ON li.listing_id = l.id
+GROUP BY l.id;
@@ -0,0 +1,8 @@
+-- size_bytes is the total size of an image's stored renditions, so a
+-- listing's storage can be capped across edits rather than per upload.
+-- Existing images are measured by the image backfill.
+ALTER TABLE listing_images
+ADD COLUMN size_bytes bigint;
+---- create above / drop below ----
+ALTER TABLE listing_images
+DROP COLUMN size_bytes;
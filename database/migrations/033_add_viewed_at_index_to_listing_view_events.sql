@@ -0,0 +1,5 @@
+-- View events are pruned once they fall out of the analytics window. This
+-- index lets the pruner find them without scanning the table.
+CREATE INDEX listing_view_events_viewed_at_idx ON listing_view_events(viewed_at);
+---- create above / drop below ----
+DROP INDEX listing_view_events_viewed_at_idx;
@@ -8,31 +8,98 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Category struct {
+	ID       string      `json:"id"`
+	ParentID pgtype.Text `json:"parent_id"`
+	Name     string      `json:"name"`
+	Slug     string      `json:"slug"`
+}
+
+type CategoryAttribute struct {
+	ID         string   `json:"id"`
+	CategoryID string   `json:"category_id"`
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"`
+	Options    []string `json:"options"`
+	Required   bool     `json:"required"`
+}
+
 type Listing struct {
-	ID          string      `json:"id"`
-	Name        string      `json:"name"`
-	Description pgtype.Text `json:"description"`
-	Price       int32       `json:"price"`
-	SellerEmail string      `json:"seller_email"`
+	ID                    string           `json:"id"`
+	Name                  string           `json:"name"`
+	Description           pgtype.Text      `json:"description"`
+	Price                 int32            `json:"price"`
+	SellerEmail           string           `json:"seller_email"`
+	Status                string           `json:"status"`
+	ReservedNegotiationID pgtype.Text      `json:"reserved_negotiation_id"`
+	SoldNegotiationID     pgtype.Text      `json:"sold_negotiation_id"`
+	CreatedAt             pgtype.Timestamp `json:"created_at"`
+	CategoryID            string           `json:"category_id"`
+	Attributes            []byte           `json:"attributes"`
+	PostalCode            pgtype.Text      `json:"postal_code"`
+	Latitude              pgtype.Float8    `json:"latitude"`
+	Longitude             pgtype.Float8    `json:"longitude"`
+	SoldAt                pgtype.Timestamp `json:"sold_at"`
+	PublishedAt           pgtype.Timestamp `json:"published_at"`
 }
 
 type ListingImage struct {
-	ListingID string `json:"listing_id"`
-	ImageUrl  string `json:"image_url"`
+	ListingID    string      `json:"listing_id"`
+	ImageUrl     string      `json:"image_url"`
+	ThumbnailUrl pgtype.Text `json:"thumbnail_url"`
+	CardUrl      pgtype.Text `json:"card_url"`
+	ID           string      `json:"id"`
+	Position     int32       `json:"position"`
+	Width        pgtype.Int4 `json:"width"`
+	SizeBytes    pgtype.Int8 `json:"size_bytes"`
+}
+
+type ListingRevision struct {
+	ID          string           `json:"id"`
+	ListingID   string           `json:"listing_id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	Price       int32            `json:"price"`
+	ImageUrls   []string         `json:"image_urls"`
+	RevisedAt   pgtype.Timestamp `json:"revised_at"`
 }
 
 type ListingView struct {
-	ListingID string `json:"listing_id"`
-	Views     int32  `json:"views"`
+	ListingID      string           `json:"listing_id"`
+	Views          int32            `json:"views"`
+	TrendingScore  float64          `json:"trending_score"`
+	ScoreUpdatedAt pgtype.Timestamp `json:"score_updated_at"`
+}
+
+type ListingViewEvent struct {
+	ID        int64            `json:"id"`
+	ListingID string           `json:"listing_id"`
+	ViewedAt  pgtype.Timestamp `json:"viewed_at"`
+	ViewerKey string           `json:"viewer_key"`
 }
 
 type ListingWithImageUrl struct {
-	ID          string      `json:"id"`
-	Name        string      `json:"name"`
-	Description pgtype.Text `json:"description"`
-	Price       int32       `json:"price"`
-	SellerEmail string      `json:"seller_email"`
-	ImageUrls   []string    `json:"image_urls"`
+	ID                    string           `json:"id"`
+	Name                  string           `json:"name"`
+	Description           pgtype.Text      `json:"description"`
+	Price                 int32            `json:"price"`
+	SellerEmail           string           `json:"seller_email"`
+	Status                string           `json:"status"`
+	ReservedNegotiationID pgtype.Text      `json:"reserved_negotiation_id"`
+	SoldNegotiationID     pgtype.Text      `json:"sold_negotiation_id"`
+	CreatedAt             pgtype.Timestamp `json:"created_at"`
+	CategoryID            string           `json:"category_id"`
+	Attributes            []byte           `json:"attributes"`
+	PostalCode            pgtype.Text      `json:"postal_code"`
+	Latitude              pgtype.Float8    `json:"latitude"`
+	Longitude             pgtype.Float8    `json:"longitude"`
+	SoldAt                pgtype.Timestamp `json:"sold_at"`
+	PublishedAt           pgtype.Timestamp `json:"published_at"`
+	ImageUrls             []string         `json:"image_urls"`
+	ThumbnailUrls         []string         `json:"thumbnail_urls"`
+	CardUrls              []string         `json:"card_urls"`
+	ImageIds              []string         `json:"image_ids"`
+	ImageWidths           []int32          `json:"image_widths"`
 }
 
 type Message struct {
@@ -46,9 +113,59 @@ type Message struct {
 }
 
 type Negotiation struct {
-	ID         string      `json:"id"`
-	ListingID  string      `json:"listing_id"`
-	BuyerEmail string      `json:"buyer_email"`
-	Bid        pgtype.Int4 `json:"bid"`
-	Ask        pgtype.Int4 `json:"ask"`
+	ID         string           `json:"id"`
+	ListingID  string           `json:"listing_id"`
+	BuyerEmail string           `json:"buyer_email"`
+	Bid        pgtype.Int4      `json:"bid"`
+	Ask        pgtype.Int4      `json:"ask"`
+	Status     string           `json:"status"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type Notification struct {
+	ID        string           `json:"id"`
+	UserEmail string           `json:"user_email"`
+	ListingID string           `json:"listing_id"`
+	Kind      string           `json:"kind"`
+	Message   string           `json:"message"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	ReadAt    pgtype.Timestamp `json:"read_at"`
+}
+
+type Offer struct {
+	ID            string           `json:"id"`
+	NegotiationID string           `json:"negotiation_id"`
+	SenderEmail   string           `json:"sender_email"`
+	Kind          string           `json:"kind"`
+	Amount        int32            `json:"amount"`
+	Status        string           `json:"status"`
+	TimeSent      pgtype.Timestamp `json:"time_sent"`
+}
+
+type PendingUpload struct {
+	Url       string           `json:"url"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type PostalCode struct {
+	PostalCode string  `json:"postal_code"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+}
+
+type SavedSearch struct {
+	ID            string           `json:"id"`
+	UserEmail     string           `json:"user_email"`
+	Name          string           `json:"name"`
+	Query         string           `json:"query"`
+	Params        []byte           `json:"params"`
+	Frequency     string           `json:"frequency"`
+	LastCheckedAt pgtype.Timestamp `json:"last_checked_at"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
+type Watchlist struct {
+	UserEmail string           `json:"user_email"`
+	ListingID string           `json:"listing_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
 }
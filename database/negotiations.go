@@ -10,3 +10,59 @@ type NegotiationQuerier interface {
 func GetOrCreateNegotiation(ctx context.Context, db NegotiationQuerier, params RecordNegotiationParams) {
 	// negotiation, err := db.NegotiationsByListingIDAndBuyerEmail()
 }
+
+const (
+	NegotiationStatusOpen         = "open"
+	NegotiationStatusOfferPending = "offer_pending"
+	NegotiationStatusAccepted     = "accepted"
+	NegotiationStatusCompleted    = "completed"
+	NegotiationStatusCancelled    = "cancelled"
+)
+
+// NegotiationStatuses lists every negotiation status in lifecycle order.
+var NegotiationStatuses = []string{
+	NegotiationStatusOpen,
+	NegotiationStatusOfferPending,
+	NegotiationStatusAccepted,
+	NegotiationStatusCompleted,
+	NegotiationStatusCancelled,
+}
+
+var negotiationTransitions = map[string][]string{
+	NegotiationStatusOpen: {
+		NegotiationStatusOfferPending,
+		NegotiationStatusCancelled,
+	},
+	NegotiationStatusOfferPending: {
+		NegotiationStatusOfferPending,
+		NegotiationStatusOpen,
+		NegotiationStatusAccepted,
+		NegotiationStatusCancelled,
+	},
+	NegotiationStatusAccepted: {
+		NegotiationStatusCompleted,
+		NegotiationStatusCancelled,
+	},
+	// A buyer bidding again on a listing reopens their cancelled negotiation.
+	NegotiationStatusCancelled: {
+		NegotiationStatusOpen,
+	},
+}
+
+// CanTransitionNegotiation reports whether a negotiation may move from one
+// status to another.
+func CanTransitionNegotiation(from, to string) bool {
+	for _, v := range negotiationTransitions[from] {
+		if v == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NegotiationAcceptsMessages reports whether chat messages may still be sent
+// on a negotiation in the given status.
+func NegotiationAcceptsMessages(status string) bool {
+	return status != NegotiationStatusCompleted && status != NegotiationStatusCancelled
+}
@@ -11,8 +11,48 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const negotiationByID = `-- name: NegotiationByID :one
+SELECT n.id, n.listing_id, n.buyer_email, n.bid, n.ask, n.status, n.created_at, l.name, l.seller_email, l.price, l.status AS listing_status
+FROM negotiations n
+JOIN listings l ON l.id = n.listing_id
+WHERE n.id = $1::text
+`
+
+type NegotiationByIDRow struct {
+	ID            string           `json:"id"`
+	ListingID     string           `json:"listing_id"`
+	BuyerEmail    string           `json:"buyer_email"`
+	Bid           pgtype.Int4      `json:"bid"`
+	Ask           pgtype.Int4      `json:"ask"`
+	Status        string           `json:"status"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	Name          string           `json:"name"`
+	SellerEmail   string           `json:"seller_email"`
+	Price         int32            `json:"price"`
+	ListingStatus string           `json:"listing_status"`
+}
+
+func (q *Queries) NegotiationByID(ctx context.Context, negotiationID string) (NegotiationByIDRow, error) {
+	row := q.db.QueryRow(ctx, negotiationByID, negotiationID)
+	var i NegotiationByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.ListingID,
+		&i.BuyerEmail,
+		&i.Bid,
+		&i.Ask,
+		&i.Status,
+		&i.CreatedAt,
+		&i.Name,
+		&i.SellerEmail,
+		&i.Price,
+		&i.ListingStatus,
+	)
+	return i, err
+}
+
 const negotiationByListingIDAndBuyerEmail = `-- name: NegotiationByListingIDAndBuyerEmail :one
-SELECT n.id, n.listing_id, n.buyer_email, n.bid, n.ask
+SELECT n.id, n.listing_id, n.buyer_email, n.bid, n.ask, n.status, n.created_at
 FROM negotiations n
 WHERE n.listing_id = $1::text
 AND n.buyer_email = $2::text
@@ -32,12 +72,14 @@ func (q *Queries) NegotiationByListingIDAndBuyerEmail(ctx context.Context, arg N
 		&i.BuyerEmail,
 		&i.Bid,
 		&i.Ask,
+		&i.Status,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const negotiationsByEmail = `-- name: NegotiationsByEmail :many
-SELECT n.id, n.listing_id, n.buyer_email, n.bid, n.ask, l.name, l.seller_email
+SELECT n.id, n.listing_id, n.buyer_email, n.bid, n.ask, n.status, n.created_at, l.name, l.seller_email
 FROM negotiations n
 LEFT JOIN listings l ON l.id = n.listing_id
 WHERE l.seller_email = $1::text
@@ -45,13 +87,15 @@ OR n.buyer_email = $1::text
 `
 
 type NegotiationsByEmailRow struct {
-	ID          string      `json:"id"`
-	ListingID   string      `json:"listing_id"`
-	BuyerEmail  string      `json:"buyer_email"`
-	Bid         pgtype.Int4 `json:"bid"`
-	Ask         pgtype.Int4 `json:"ask"`
-	Name        pgtype.Text `json:"name"`
-	SellerEmail pgtype.Text `json:"seller_email"`
+	ID          string           `json:"id"`
+	ListingID   string           `json:"listing_id"`
+	BuyerEmail  string           `json:"buyer_email"`
+	Bid         pgtype.Int4      `json:"bid"`
+	Ask         pgtype.Int4      `json:"ask"`
+	Status      string           `json:"status"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	Name        pgtype.Text      `json:"name"`
+	SellerEmail pgtype.Text      `json:"seller_email"`
 }
 
 func (q *Queries) NegotiationsByEmail(ctx context.Context, email string) ([]NegotiationsByEmailRow, error) {
@@ -69,6 +113,8 @@ func (q *Queries) NegotiationsByEmail(ctx context.Context, email string) ([]Nego
 			&i.BuyerEmail,
 			&i.Bid,
 			&i.Ask,
+			&i.Status,
+			&i.CreatedAt,
 			&i.Name,
 			&i.SellerEmail,
 		); err != nil {
@@ -91,7 +137,7 @@ VALUES(
 )
 ON CONFLICT(listing_id, buyer_email)
 DO NOTHING
-RETURNING id, listing_id, buyer_email, bid, ask
+RETURNING id, listing_id, buyer_email, bid, ask, status, created_at
 `
 
 type RecordNegotiationParams struct {
@@ -108,6 +154,91 @@ func (q *Queries) RecordNegotiation(ctx context.Context, arg RecordNegotiationPa
 		&i.BuyerEmail,
 		&i.Bid,
 		&i.Ask,
+		&i.Status,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateNegotiationAsk = `-- name: UpdateNegotiationAsk :one
+UPDATE negotiations
+SET ask = $1::int
+WHERE id = $2::text
+RETURNING id, listing_id, buyer_email, bid, ask, status, created_at
+`
+
+type UpdateNegotiationAskParams struct {
+	Ask           int32  `json:"ask"`
+	NegotiationID string `json:"negotiation_id"`
+}
+
+func (q *Queries) UpdateNegotiationAsk(ctx context.Context, arg UpdateNegotiationAskParams) (Negotiation, error) {
+	row := q.db.QueryRow(ctx, updateNegotiationAsk, arg.Ask, arg.NegotiationID)
+	var i Negotiation
+	err := row.Scan(
+		&i.ID,
+		&i.ListingID,
+		&i.BuyerEmail,
+		&i.Bid,
+		&i.Ask,
+		&i.Status,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateNegotiationBid = `-- name: UpdateNegotiationBid :one
+UPDATE negotiations
+SET bid = $1::int
+WHERE id = $2::text
+RETURNING id, listing_id, buyer_email, bid, ask, status, created_at
+`
+
+type UpdateNegotiationBidParams struct {
+	Bid           int32  `json:"bid"`
+	NegotiationID string `json:"negotiation_id"`
+}
+
+func (q *Queries) UpdateNegotiationBid(ctx context.Context, arg UpdateNegotiationBidParams) (Negotiation, error) {
+	row := q.db.QueryRow(ctx, updateNegotiationBid, arg.Bid, arg.NegotiationID)
+	var i Negotiation
+	err := row.Scan(
+		&i.ID,
+		&i.ListingID,
+		&i.BuyerEmail,
+		&i.Bid,
+		&i.Ask,
+		&i.Status,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateNegotiationStatus = `-- name: UpdateNegotiationStatus :one
+UPDATE negotiations
+SET status = $1::text
+WHERE id = $2::text
+AND status = $3::text
+RETURNING id, listing_id, buyer_email, bid, ask, status, created_at
+`
+
+type UpdateNegotiationStatusParams struct {
+	Status        string `json:"status"`
+	NegotiationID string `json:"negotiation_id"`
+	FromStatus    string `json:"from_status"`
+}
+
+func (q *Queries) UpdateNegotiationStatus(ctx context.Context, arg UpdateNegotiationStatusParams) (Negotiation, error) {
+	row := q.db.QueryRow(ctx, updateNegotiationStatus, arg.Status, arg.NegotiationID, arg.FromStatus)
+	var i Negotiation
+	err := row.Scan(
+		&i.ID,
+		&i.ListingID,
+		&i.BuyerEmail,
+		&i.Bid,
+		&i.Ask,
+		&i.Status,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,28 @@
+package database
+
+import "fmt"
+
+const (
+	NotificationKindPriceDrop = "price_drop"
+	NotificationKindReserved  = "reserved"
+	NotificationKindSold      = "sold"
+	NotificationKindDeleted   = "deleted"
+	NotificationKindSearch    = "saved_search"
+)
+
+// NotificationMessage describes a change to a watched listing. newPrice is
+// only used for price drops.
+func NotificationMessage(kind string, listing ListingWithImageUrl, newPrice int32) string {
+	switch kind {
+	case NotificationKindPriceDrop:
+		return fmt.Sprintf("%s dropped from $%.2f to $%.2f", listing.Name, float32(listing.Price)/100, float32(newPrice)/100)
+	case NotificationKindReserved:
+		return fmt.Sprintf("%s has been reserved", listing.Name)
+	case NotificationKindSold:
+		return fmt.Sprintf("%s has sold", listing.Name)
+	case NotificationKindDeleted:
+		return fmt.Sprintf("%s is no longer available", listing.Name)
+	}
+
+	return fmt.Sprintf("%s was updated", listing.Name)
+}
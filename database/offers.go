@@ -0,0 +1,13 @@
+package database
+
+const (
+	OfferKindOffer   = "offer"
+	OfferKindCounter = "counter"
+)
+
+const (
+	OfferStatusPending    = "pending"
+	OfferStatusAccepted   = "accepted"
+	OfferStatusRejected   = "rejected"
+	OfferStatusSuperseded = "superseded"
+)
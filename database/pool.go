@@ -0,0 +1,26 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// wordSimilarityThreshold is the lowest word_similarity at which a listing
+// name counts as a typo-tolerant match.
+const wordSimilarityThreshold = "0.3"
+
+// NewPool connects to url with the session settings Jolt's queries expect.
+func NewPool(ctx context.Context, url string) (*pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+
+	// The <% operator compares against this setting rather than taking a
+	// threshold argument, which is what lets it use the trigram index on
+	// listings.name.
+	config.ConnConfig.RuntimeParams["pg_trgm.word_similarity_threshold"] = wordSimilarityThreshold
+
+	return pgxpool.NewWithConfig(ctx, config)
+}
@@ -6,23 +6,88 @@ package database
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AddToWatchlist(ctx context.Context, arg AddToWatchlistParams) error
+	CategoriesWithListingCounts(ctx context.Context) ([]CategoriesWithListingCountsRow, error)
+	CategoryAncestorIDs(ctx context.Context, categoryID string) ([]string, error)
+	CategoryAttributes(ctx context.Context) ([]CategoryAttribute, error)
+	CategoryAttributesByCategoryID(ctx context.Context, categoryID string) ([]CategoryAttribute, error)
+	CategoryByID(ctx context.Context, categoryID string) (Category, error)
+	CategoryBySlug(ctx context.Context, slug string) (Category, error)
+	CommitPendingUploads(ctx context.Context, urlArray []string) error
+	DeleteCategory(ctx context.Context, categoryID string) (Category, error)
+	DeleteCategoryAttribute(ctx context.Context, attributeID string) (CategoryAttribute, error)
 	DeleteListing(ctx context.Context, listingID string) (Listing, error)
+	DeleteListingImages(ctx context.Context, arg DeleteListingImagesParams) error
+	DeletePendingUpload(ctx context.Context, url string) error
+	DeleteSavedSearch(ctx context.Context, arg DeleteSavedSearchParams) (int64, error)
+	DueSavedSearches(ctx context.Context, frequency pgtype.Text) ([]DueSavedSearchesRow, error)
+	FirstListingRevisionSince(ctx context.Context, arg FirstListingRevisionSinceParams) (ListingRevision, error)
+	IsWatching(ctx context.Context, arg IsWatchingParams) (bool, error)
 	ListingByID(ctx context.Context, listingID string) (ListingWithImageUrl, error)
+	ListingImageByID(ctx context.Context, imageID string) (ListingImage, error)
+	ListingImagesSize(ctx context.Context, listingID string) (int64, error)
+	ListingRevisionsByListingID(ctx context.Context, listingID string) ([]ListingRevision, error)
 	ListingViewsByID(ctx context.Context, listingID string) (int32, error)
-	ListingsByLikeName(ctx context.Context, listingName string) ([]ListingWithImageUrl, error)
-	ListingsBySellerEmail(ctx context.Context, sellerEmail string) ([]ListingWithImageUrl, error)
-	ListingsByViews(ctx context.Context, arg ListingsByViewsParams) ([]ListingWithImageUrl, error)
+	ListingsBySellerEmail(ctx context.Context, arg ListingsBySellerEmailParams) ([]ListingWithImageUrl, error)
+	LockListingViewer(ctx context.Context, arg LockListingViewerParams) error
+	MarkNotificationsRead(ctx context.Context, userEmail string) error
+	MarkSavedSearchChecked(ctx context.Context, arg MarkSavedSearchCheckedParams) error
 	MessagesByNegotiationID(ctx context.Context, negotiationID string) ([]Message, error)
+	NegotiationByID(ctx context.Context, negotiationID string) (NegotiationByIDRow, error)
 	NegotiationByListingIDAndBuyerEmail(ctx context.Context, arg NegotiationByListingIDAndBuyerEmailParams) (Negotiation, error)
 	NegotiationsByEmail(ctx context.Context, email string) ([]NegotiationsByEmailRow, error)
+	NotificationsByEmail(ctx context.Context, userEmail string) ([]Notification, error)
+	NotifyWatchers(ctx context.Context, arg NotifyWatchersParams) error
+	OfferByID(ctx context.Context, offerID string) (Offer, error)
+	OffersByNegotiationID(ctx context.Context, negotiationID string) ([]Offer, error)
+	OrphanedUploads(ctx context.Context, maxCount int32) ([]string, error)
+	PostalCodeByCode(ctx context.Context, postalCode string) (PostalCode, error)
+	PruneListingViewEvents(ctx context.Context, arg PruneListingViewEventsParams) (int64, error)
+	RecordCategory(ctx context.Context, arg RecordCategoryParams) (Category, error)
+	RecordCategoryAttribute(ctx context.Context, arg RecordCategoryAttributeParams) (CategoryAttribute, error)
 	RecordListing(ctx context.Context, arg RecordListingParams) (Listing, error)
 	RecordListingImages(ctx context.Context, arg RecordListingImagesParams) ([]ListingImage, error)
+	RecordListingRevision(ctx context.Context, listingID string) (ListingRevision, error)
+	RecordListingViewEvent(ctx context.Context, arg RecordListingViewEventParams) (int64, error)
 	RecordMessage(ctx context.Context, arg RecordMessageParams) (Message, error)
 	RecordNegotiation(ctx context.Context, arg RecordNegotiationParams) (Negotiation, error)
+	RecordNotification(ctx context.Context, arg RecordNotificationParams) error
+	RecordOffer(ctx context.Context, arg RecordOfferParams) (Offer, error)
+	RecordPendingUpload(ctx context.Context, url string) error
+	RecordPendingUploads(ctx context.Context, urlArray []string) error
+	RecordSavedSearch(ctx context.Context, arg RecordSavedSearchParams) (SavedSearch, error)
+	RefreshSearchTerms(ctx context.Context) error
+	ReleaseListingImages(ctx context.Context, listingID string) error
+	RemoveFromWatchlist(ctx context.Context, arg RemoveFromWatchlistParams) error
+	ReorderListingImages(ctx context.Context, arg ReorderListingImagesParams) error
+	ReplaceListingImageVariants(ctx context.Context, arg ReplaceListingImageVariantsParams) (int64, error)
+	SavedSearchesByEmail(ctx context.Context, userEmail string) ([]SavedSearch, error)
+	SearchListings(ctx context.Context, arg SearchListingsParams) ([]ListingWithImageUrl, error)
+	SellerListingStats(ctx context.Context, arg SellerListingStatsParams) ([]SellerListingStatsRow, error)
+	SellerViewsByDay(ctx context.Context, arg SellerViewsByDayParams) ([]SellerViewsByDayRow, error)
+	SuggestSearchTerm(ctx context.Context, term string) (string, error)
+	SupersedePendingOffers(ctx context.Context, negotiationID string) error
+	TrendingListings(ctx context.Context, arg TrendingListingsParams) ([]ListingWithImageUrl, error)
+	UnreadNotificationCount(ctx context.Context, userEmail string) (int32, error)
+	UnsizedListingImages(ctx context.Context, arg UnsizedListingImagesParams) ([]ListingImage, error)
+	UntrackedBlobs(ctx context.Context, urlArray []string) ([]string, error)
+	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error)
+	UpdateListing(ctx context.Context, arg UpdateListingParams) (Listing, error)
+	UpdateListingStatus(ctx context.Context, arg UpdateListingStatusParams) (Listing, error)
+	UpdateNegotiationAsk(ctx context.Context, arg UpdateNegotiationAskParams) (Negotiation, error)
+	UpdateNegotiationBid(ctx context.Context, arg UpdateNegotiationBidParams) (Negotiation, error)
+	UpdateNegotiationStatus(ctx context.Context, arg UpdateNegotiationStatusParams) (Negotiation, error)
+	UpdateOfferStatus(ctx context.Context, arg UpdateOfferStatusParams) (Offer, error)
+	UpdateSavedSearchFrequency(ctx context.Context, arg UpdateSavedSearchFrequencyParams) (SavedSearch, error)
 	UpsertListingViews(ctx context.Context, listingID string) (ListingView, error)
+	UpsertPostalCode(ctx context.Context, arg UpsertPostalCodeParams) error
+	WatchedListingIDs(ctx context.Context, arg WatchedListingIDsParams) ([]string, error)
+	WatchedListings(ctx context.Context, userEmail string) ([]ListingWithImageUrl, error)
 }
 
 var _ Querier = (*Queries)(nil)
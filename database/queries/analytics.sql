@@ -0,0 +1,50 @@
+-- name: SellerListingStats :many
+SELECT l.id, l.name, l.status, l.price, l.created_at, l.sold_at,
+    COALESCE(lv.views, 0)::int AS views,
+    COALESCE(n.negotiation_count, 0)::int AS negotiation_count,
+    COALESCE(o.offer_count, 0)::int AS offer_count,
+    COALESCE(o.median_offer, 0)::float8 AS median_offer,
+    COALESCE(m.message_count, 0)::int AS message_count
+FROM listings l
+LEFT JOIN (
+    SELECT e.listing_id, COUNT(*) AS views
+    FROM listing_view_events e
+    WHERE e.viewed_at >= CURRENT_DATE - (@days::int - 1)
+    GROUP BY e.listing_id
+) lv ON lv.listing_id = l.id
+LEFT JOIN (
+    SELECT n.listing_id, COUNT(*) AS negotiation_count
+    FROM negotiations n
+    GROUP BY n.listing_id
+) n ON n.listing_id = l.id
+LEFT JOIN (
+    SELECT n.listing_id, COUNT(*) AS offer_count, percentile_cont(0.5) WITHIN GROUP (ORDER BY o.amount) AS median_offer
+    FROM offers o
+    JOIN negotiations n ON n.id = o.negotiation_id
+    WHERE o.kind = 'offer'
+    GROUP BY n.listing_id
+) o ON o.listing_id = l.id
+LEFT JOIN (
+    SELECT n.listing_id, COUNT(*) AS message_count
+    FROM messages m
+    JOIN negotiations n ON n.id = m.negotiation_id
+    GROUP BY n.listing_id
+) m ON m.listing_id = l.id
+WHERE UPPER(l.seller_email) = UPPER(@seller_email::text)
+AND (sqlc.narg(listing_id)::text IS NULL OR l.id = sqlc.narg(listing_id)::text)
+ORDER BY l.created_at DESC;
+
+-- name: SellerViewsByDay :many
+SELECT d.day::date AS day, COUNT(e.id)::int AS views
+FROM generate_series(CURRENT_DATE - (@days::int - 1), CURRENT_DATE, interval '1 day') AS d(day)
+LEFT JOIN listing_view_events e
+    ON e.viewed_at >= d.day::date
+    AND e.viewed_at < d.day::date + 1
+    AND e.listing_id IN (
+        SELECT l.id
+        FROM listings l
+        WHERE UPPER(l.seller_email) = UPPER(@seller_email::text)
+        AND (sqlc.narg(listing_id)::text IS NULL OR l.id = sqlc.narg(listing_id)::text)
+    )
+GROUP BY d.day
+ORDER BY d.day ASC;
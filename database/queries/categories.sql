@@ -0,0 +1,60 @@
+-- name: CategoryByID :one
+SELECT c.*
+FROM categories c
+WHERE c.id = @category_id::text;
+
+-- name: CategoryBySlug :one
+SELECT c.*
+FROM categories c
+WHERE c.slug = @slug::text;
+
+-- name: CategoriesWithListingCounts :many
+WITH RECURSIVE tree AS (
+    SELECT c.id AS root_id, c.id
+    FROM categories c
+    UNION ALL
+    SELECT t.root_id, c.id
+    FROM categories c
+    JOIN tree t ON c.parent_id = t.id
+)
+SELECT c.id, c.parent_id, c.name, c.slug, COUNT(l.id)::int AS listing_count
+FROM categories c
+JOIN tree t ON t.root_id = c.id
+LEFT JOIN listings l ON l.category_id = t.id AND l.status = 'active'
+GROUP BY c.id
+ORDER BY c.name ASC;
+
+-- name: CategoryAncestorIDs :many
+WITH RECURSIVE ancestors AS (
+    SELECT c.id, c.parent_id
+    FROM categories c
+    WHERE c.id = @category_id::text
+    UNION ALL
+    SELECT c.id, c.parent_id
+    FROM categories c
+    JOIN ancestors a ON c.id = a.parent_id
+)
+SELECT a.id::text
+FROM ancestors a;
+
+-- name: RecordCategory :one
+INSERT INTO categories(id, parent_id, name, slug) VALUES(
+    @id::text,
+    sqlc.narg(parent_id)::text,
+    @category_name::text,
+    @slug::text
+)
+RETURNING *;
+
+-- name: UpdateCategory :one
+UPDATE categories
+SET parent_id = sqlc.narg(parent_id)::text,
+    name = @category_name::text,
+    slug = @slug::text
+WHERE id = @category_id::text
+RETURNING *;
+
+-- name: DeleteCategory :one
+DELETE FROM categories c
+WHERE c.id = @category_id::text
+RETURNING *;
@@ -0,0 +1,35 @@
+-- name: CategoryAttributes :many
+SELECT ca.*
+FROM category_attributes ca
+ORDER BY ca.category_id ASC, ca.name ASC;
+
+-- name: CategoryAttributesByCategoryID :many
+WITH RECURSIVE ancestors AS (
+    SELECT c.id, c.parent_id
+    FROM categories c
+    WHERE c.id = @category_id::text
+    UNION ALL
+    SELECT c.id, c.parent_id
+    FROM categories c
+    JOIN ancestors a ON c.id = a.parent_id
+)
+SELECT ca.*
+FROM category_attributes ca
+JOIN ancestors a ON a.id = ca.category_id
+ORDER BY ca.name ASC;
+
+-- name: RecordCategoryAttribute :one
+INSERT INTO category_attributes(id, category_id, name, kind, options, required) VALUES(
+    @id::text,
+    @category_id::text,
+    @attribute_name::text,
+    @kind::text,
+    @options::text[],
+    @required::boolean
+)
+RETURNING *;
+
+-- name: DeleteCategoryAttribute :one
+DELETE FROM category_attributes ca
+WHERE ca.id = @attribute_id::text
+RETURNING *;
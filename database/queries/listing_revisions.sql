@@ -0,0 +1,20 @@
+-- name: RecordListingRevision :one
+INSERT INTO listing_revisions(id, listing_id, name, description, price, image_urls)
+SELECT uuid_generate_v4(), l.id, l.name, l.description, l.price, l.image_urls
+FROM listing_with_image_urls l
+WHERE l.id = @listing_id::text
+RETURNING *;
+
+-- name: ListingRevisionsByListingID :many
+SELECT r.*
+FROM listing_revisions r
+WHERE r.listing_id = @listing_id::text
+ORDER BY r.revised_at DESC;
+
+-- name: FirstListingRevisionSince :one
+SELECT r.*
+FROM listing_revisions r
+WHERE r.listing_id = @listing_id::text
+AND r.revised_at >= @since::timestamp
+ORDER BY r.revised_at ASC
+LIMIT 1;
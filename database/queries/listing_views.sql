@@ -4,10 +4,42 @@ FROM listing_views l
 WHERE l.listing_id = @listing_id::text;
 
 -- name: UpsertListingViews :one
-INSERT INTO listing_views(listing_id) VALUES(
-    @listing_id::text
+INSERT INTO listing_views(listing_id, views, trending_score, score_updated_at) VALUES(
+    @listing_id::text,
+    1,
+    1,
+    NOW()
 )
 ON CONFLICT(listing_id)
 DO UPDATE SET
-views = listing_views.views+1
+views = listing_views.views+1,
+trending_score = decayed_score(listing_views.trending_score, listing_views.score_updated_at)+1,
+score_updated_at = NOW()
 RETURNING *;
+
+-- name: LockListingViewer :exec
+-- Serializes RecordListingViewEvent for one viewer of a listing until the
+-- transaction ends, so concurrent views can't both pass the dedupe check.
+SELECT pg_advisory_xact_lock(hashtext(@listing_id::text || ':' || @viewer_key::text));
+
+-- name: RecordListingViewEvent :execrows
+INSERT INTO listing_view_events(listing_id, viewer_key)
+SELECT @listing_id::text, @viewer_key::text
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM listing_view_events e
+    WHERE e.listing_id = @listing_id::text
+    AND e.viewer_key = @viewer_key::text
+    AND e.viewed_at > NOW() - make_interval(secs => @dedupe_seconds::int)
+);
+
+-- name: PruneListingViewEvents :execrows
+-- Deletes up to max_count view events recorded before the last
+-- retention_days days.
+DELETE FROM listing_view_events
+WHERE id IN (
+    SELECT e.id
+    FROM listing_view_events e
+    WHERE e.viewed_at < CURRENT_DATE - @retention_days::int
+    LIMIT @max_count::int
+);
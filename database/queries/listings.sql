@@ -3,42 +3,191 @@ SELECT l.*
 FROM listing_with_image_urls l
 WHERE l.id = @listing_id::text;
 
--- name: ListingsByViews :many
-SELECT l.*
-FROM listing_with_image_urls l
-JOIN listing_views lv ON lv.listing_id = l.id
-ORDER BY lv.views DESC
-LIMIT $1
-OFFSET $2;
-
--- name: ListingsByLikeName :many
-SELECT l.*
-FROM listing_with_image_urls l
-WHERE UPPER(l.name) LIKE UPPER('%' || @listing_name::text || '%');
-
 -- name: ListingsBySellerEmail :many
 SELECT l.*
 FROM listing_with_image_urls l
-WHERE UPPER(l.seller_email) = UPPER(@seller_email::text);
+WHERE UPPER(l.seller_email) = UPPER(@seller_email::text)
+AND (@status::text = '' OR l.status = @status::text);
 
 -- name: RecordListing :one
-INSERT INTO listings(id, seller_email, name, description, price) VALUES(
+INSERT INTO listings(id, seller_email, name, description, price, status, category_id, attributes, postal_code, latitude, longitude, published_at) VALUES(
     @id::text,
     @seller_email::text,
     @listing_name::text,
     @description::text,
-    @price::int
+    @price::int,
+    @status::text,
+    @category_id::text,
+    @attributes::jsonb,
+    sqlc.narg(postal_code)::text,
+    sqlc.narg(latitude)::float8,
+    sqlc.narg(longitude)::float8,
+    CASE WHEN @status::text = 'active' THEN NOW() END
 )
 RETURNING *;
 
 -- name: RecordListingImages :many
-INSERT INTO listing_images(listing_id, image_url) VALUES(
-    @listing_id::text,
-    unnest(@image_url_array::text[])
-)
+INSERT INTO listing_images(listing_id, image_url, thumbnail_url, card_url, width, size_bytes, position)
+SELECT @listing_id::text, u.image_url, u.thumbnail_url, u.card_url, u.width, u.size_bytes,
+    (SELECT COALESCE(MAX(li.position) + 1, 0) FROM listing_images li WHERE li.listing_id = @listing_id::text) + u.n - 1
+FROM unnest(@image_url_array::text[], @thumbnail_url_array::text[], @card_url_array::text[], @width_array::int[], @size_array::bigint[]) WITH ORDINALITY AS u(image_url, thumbnail_url, card_url, width, size_bytes, n)
 RETURNING *;
 
+-- name: UnsizedListingImages :many
+-- Images stored before their width or size was recorded, including every
+-- image stored before variants, in ID order after after_id.
+SELECT li.*
+FROM listing_images li
+WHERE (li.width IS NULL OR li.size_bytes IS NULL)
+AND li.id > @after_id::text
+ORDER BY li.id
+LIMIT @max_count::int;
+
+-- name: ReplaceListingImageVariants :execrows
+-- Matches nothing if the image was deleted or replaced in the meantime.
+UPDATE listing_images
+SET image_url = @image_url::text,
+    thumbnail_url = @thumbnail_url::text,
+    card_url = @card_url::text,
+    width = @width::int,
+    size_bytes = @size_bytes::bigint
+WHERE id = @image_id::text
+AND image_url = @old_image_url::text;
+
+-- name: ListingImagesSize :one
+SELECT COALESCE(SUM(li.size_bytes), 0)::bigint
+FROM listing_images li
+WHERE li.listing_id = @listing_id::text;
+
+-- name: ReorderListingImages :exec
+UPDATE listing_images li
+SET position = o.n - 1
+FROM unnest(@image_id_array::text[]) WITH ORDINALITY AS o(id, n)
+WHERE li.listing_id = @listing_id::text
+AND li.id = o.id;
+
 -- name: DeleteListing :one
 DELETE FROM listings l
 WHERE l.id = @listing_id::text
 RETURNING *;
+
+-- name: UpdateListingStatus :one
+-- Each transition writes only the columns it sets. A released reservation
+-- clears its negotiation, while a sale's negotiation and time survive
+-- archiving and are cleared only when an archived listing is relisted. The
+-- first activation stamps published_at.
+UPDATE listings
+SET status = @status::text,
+    reserved_negotiation_id = CASE
+        WHEN @status::text = 'active' THEN NULL
+        ELSE COALESCE(sqlc.narg(reserved_negotiation_id)::text, reserved_negotiation_id)
+    END,
+    sold_negotiation_id = CASE
+        WHEN status = 'archived' AND @status::text = 'active' THEN NULL
+        ELSE COALESCE(sqlc.narg(sold_negotiation_id)::text, sold_negotiation_id)
+    END,
+    sold_at = CASE
+        WHEN @status::text = 'sold' THEN NOW()
+        WHEN status = 'archived' AND @status::text = 'active' THEN NULL
+        ELSE sold_at
+    END,
+    published_at = CASE
+        WHEN @status::text = 'active' THEN COALESCE(published_at, NOW())
+        ELSE published_at
+    END
+WHERE id = @listing_id::text
+AND status = @from_status::text
+RETURNING *;
+
+-- name: UpdateListing :one
+UPDATE listings
+SET name = @listing_name::text,
+    description = @description::text,
+    price = @price::int
+WHERE id = @listing_id::text
+RETURNING *;
+
+-- name: DeleteListingImages :exec
+WITH deleted AS (
+    DELETE FROM listing_images
+    WHERE listing_id = @listing_id::text
+    AND id = ANY(@image_id_array::text[])
+    RETURNING image_url, thumbnail_url, card_url
+)
+INSERT INTO pending_uploads(url)
+SELECT u.url
+FROM deleted d, unnest(ARRAY[d.image_url, d.thumbnail_url, d.card_url]) AS u(url)
+WHERE u.url IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- name: ListingImageByID :one
+SELECT li.*
+FROM listing_images li
+WHERE li.id = @image_id::text;
+
+-- name: SuggestSearchTerm :one
+-- Candidates come from the trigram index, so a misspelling that shares few
+-- trigrams with the word, as in very short words, goes unsuggested.
+SELECT v.word::text
+FROM search_terms v
+WHERE v.word % @term::text
+AND levenshtein(v.word, @term::text) <= 2
+ORDER BY levenshtein(v.word, @term::text) ASC, v.ndoc DESC
+LIMIT 1;
+
+-- name: RefreshSearchTerms :exec
+REFRESH MATERIALIZED VIEW CONCURRENTLY search_terms;
+
+-- name: SearchListings :many
+-- Radius searches are also limited to a band of latitudes, which
+-- listings_location_idx can serve, before distance_km is computed per row. A
+-- degree of latitude spans about 111.19 km, so the band never excludes a
+-- listing within the radius.
+SELECT l.*
+FROM listing_with_image_urls l
+LEFT JOIN (
+    SELECT ls.id, ts_rank(listing_search_vector(ls.name, ls.description), to_tsquery('english', @search_query::text)) AS rank
+    FROM listings ls
+    WHERE @search_query::text <> ''
+    AND listing_search_vector(ls.name, ls.description) @@ to_tsquery('english', @search_query::text)
+    UNION ALL
+    SELECT ls.id, word_similarity(@similar_name::text, ls.name) AS rank
+    FROM listings ls
+    WHERE @similar_name::text <> ''
+    AND @similar_name::text <% ls.name
+) matches ON matches.id = l.id
+LEFT JOIN listing_views lv ON lv.listing_id = l.id
+WHERE l.status = 'active'
+AND ((@search_query::text = '' AND @similar_name::text = '') OR matches.id IS NOT NULL)
+AND (sqlc.narg(min_price)::int IS NULL OR l.price >= sqlc.narg(min_price)::int)
+AND (sqlc.narg(max_price)::int IS NULL OR l.price <= sqlc.narg(max_price)::int)
+AND (NOT @has_photos::boolean OR cardinality(l.image_urls) > 0)
+AND (@seller_email::text = '' OR UPPER(l.seller_email) = UPPER(@seller_email::text))
+AND (sqlc.narg(category_id)::text IS NULL OR l.category_id IN (SELECT category_tree(sqlc.narg(category_id)::text)))
+AND (sqlc.narg(attributes)::jsonb IS NULL OR l.attributes @> sqlc.narg(attributes)::jsonb)
+AND (sqlc.narg(radius_km)::float8 IS NULL OR distance_km(sqlc.narg(origin_latitude)::float8, sqlc.narg(origin_longitude)::float8, l.latitude, l.longitude) <= sqlc.narg(radius_km)::float8)
+AND (sqlc.narg(radius_km)::float8 IS NULL OR l.latitude BETWEEN sqlc.narg(origin_latitude)::float8 - sqlc.narg(radius_km)::float8 / 111.19 AND sqlc.narg(origin_latitude)::float8 + sqlc.narg(radius_km)::float8 / 111.19)
+AND (sqlc.narg(published_after)::timestamp IS NULL OR l.published_at > sqlc.narg(published_after)::timestamp)
+ORDER BY
+    CASE WHEN @sort::text = 'price_asc' THEN l.price END ASC,
+    CASE WHEN @sort::text = 'price_desc' THEN l.price END DESC,
+    CASE WHEN @sort::text = 'newest' THEN l.created_at END DESC,
+    CASE WHEN @sort::text = 'most_viewed' THEN COALESCE(lv.views, 0) END DESC,
+    CASE WHEN @sort::text = 'distance' THEN distance_km(sqlc.narg(origin_latitude)::float8, sqlc.narg(origin_longitude)::float8, l.latitude, l.longitude) END ASC NULLS LAST,
+    matches.rank DESC NULLS LAST,
+    l.created_at DESC
+LIMIT @page_size::int
+OFFSET @page_offset::int;
+
+-- name: TrendingListings :many
+SELECT l.*
+FROM listing_with_image_urls l
+LEFT JOIN listing_views lv ON lv.listing_id = l.id
+WHERE l.status = 'active'
+AND (sqlc.narg(category_id)::text IS NULL OR l.category_id IN (SELECT category_tree(sqlc.narg(category_id)::text)))
+ORDER BY
+    CASE WHEN @sort::text = 'distance' THEN distance_km(sqlc.narg(origin_latitude)::float8, sqlc.narg(origin_longitude)::float8, l.latitude, l.longitude) END ASC NULLS LAST,
+    COALESCE(decayed_score(lv.trending_score, lv.score_updated_at), 0) DESC,
+    l.created_at DESC
+LIMIT @page_size::int
+OFFSET @page_offset::int;
@@ -21,3 +21,28 @@ SELECT n.*
 FROM negotiations n
 WHERE n.listing_id = @listing_id::text
 AND n.buyer_email = @buyer_email::text;
+
+-- name: NegotiationByID :one
+SELECT n.*, l.name, l.seller_email, l.price, l.status AS listing_status
+FROM negotiations n
+JOIN listings l ON l.id = n.listing_id
+WHERE n.id = @negotiation_id::text;
+
+-- name: UpdateNegotiationBid :one
+UPDATE negotiations
+SET bid = @bid::int
+WHERE id = @negotiation_id::text
+RETURNING *;
+
+-- name: UpdateNegotiationAsk :one
+UPDATE negotiations
+SET ask = @ask::int
+WHERE id = @negotiation_id::text
+RETURNING *;
+
+-- name: UpdateNegotiationStatus :one
+UPDATE negotiations
+SET status = @status::text
+WHERE id = @negotiation_id::text
+AND status = @from_status::text
+RETURNING *;
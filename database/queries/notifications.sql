@@ -0,0 +1,36 @@
+-- name: NotifyWatchers :exec
+INSERT INTO notifications(id, user_email, listing_id, kind, message)
+SELECT uuid_generate_v4(), w.user_email, w.listing_id, @kind::text, @message::text
+FROM watchlist w
+WHERE w.listing_id = @listing_id::text;
+
+-- name: NotificationsByEmail :many
+SELECT n.*
+FROM notifications n
+WHERE n.user_email = @user_email::text
+ORDER BY n.created_at DESC
+LIMIT 50;
+
+-- name: UnreadNotificationCount :one
+SELECT COUNT(*)::int
+FROM notifications n
+WHERE n.user_email = @user_email::text
+AND n.read_at IS NULL;
+
+-- name: MarkNotificationsRead :exec
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_email = @user_email::text
+AND read_at IS NULL;
+
+-- name: RecordNotification :exec
+-- Saved search matches are unique per user and listing, so matching a listing
+-- again is a no-op.
+INSERT INTO notifications(id, user_email, listing_id, kind, message) VALUES(
+    uuid_generate_v4(),
+    @user_email::text,
+    @listing_id::text,
+    @kind::text,
+    @message::text
+)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,34 @@
+-- name: RecordOffer :one
+INSERT INTO offers(id, negotiation_id, sender_email, kind, amount)
+VALUES(
+    uuid_generate_v4(),
+    @negotiation_id::text,
+    @sender_email::text,
+    @kind::text,
+    @amount::int
+)
+RETURNING *;
+
+-- name: OfferByID :one
+SELECT o.*
+FROM offers o
+WHERE o.id = @offer_id::text;
+
+-- name: OffersByNegotiationID :many
+SELECT o.*
+FROM offers o
+WHERE o.negotiation_id = @negotiation_id::text
+ORDER BY o.time_sent ASC;
+
+-- name: UpdateOfferStatus :one
+UPDATE offers
+SET status = @status::text
+WHERE id = @offer_id::text
+AND status = 'pending'
+RETURNING *;
+
+-- name: SupersedePendingOffers :exec
+UPDATE offers
+SET status = 'superseded'
+WHERE negotiation_id = @negotiation_id::text
+AND status = 'pending';
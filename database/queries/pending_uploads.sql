@@ -0,0 +1,52 @@
+-- name: RecordPendingUpload :exec
+INSERT INTO pending_uploads(url) VALUES(@url::text)
+ON CONFLICT DO NOTHING;
+
+-- name: RecordPendingUploads :exec
+INSERT INTO pending_uploads(url)
+SELECT unnest(@url_array::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: CommitPendingUploads :exec
+DELETE FROM pending_uploads p
+WHERE p.url = ANY(@url_array::text[]);
+
+-- name: ReleaseListingImages :exec
+INSERT INTO pending_uploads(url)
+SELECT u.url
+FROM listing_images li, unnest(ARRAY[li.image_url, li.thumbnail_url, li.card_url]) AS u(url)
+WHERE li.listing_id = @listing_id::text
+AND u.url IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- name: OrphanedUploads :many
+SELECT p.url
+FROM pending_uploads p
+WHERE p.created_at < NOW() - INTERVAL '1 hour'
+AND NOT EXISTS(
+    SELECT 1
+    FROM listing_images li
+    WHERE p.url IN (li.image_url, li.thumbnail_url, li.card_url)
+)
+ORDER BY p.created_at
+LIMIT @max_count::int;
+
+-- name: DeletePendingUpload :exec
+DELETE FROM pending_uploads p
+WHERE p.url = @url::text;
+
+-- name: UntrackedBlobs :many
+-- Narrows url_array to the blobs that neither a listing image nor a pending
+-- upload refers to.
+SELECT u.url::text
+FROM unnest(@url_array::text[]) AS u(url)
+WHERE NOT EXISTS(
+    SELECT 1
+    FROM listing_images li
+    WHERE u.url IN (li.image_url, li.thumbnail_url, li.card_url)
+)
+AND NOT EXISTS(
+    SELECT 1
+    FROM pending_uploads p
+    WHERE p.url = u.url
+);
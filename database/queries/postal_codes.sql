@@ -0,0 +1,10 @@
+-- name: PostalCodeByCode :one
+SELECT p.*
+FROM postal_codes p
+WHERE p.postal_code = @postal_code::text;
+
+-- name: UpsertPostalCode :exec
+INSERT INTO postal_codes (postal_code, latitude, longitude)
+VALUES (@postal_code::text, @latitude::float8, @longitude::float8)
+ON CONFLICT (postal_code) DO UPDATE
+SET latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude;
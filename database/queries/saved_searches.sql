@@ -0,0 +1,44 @@
+-- name: RecordSavedSearch :one
+INSERT INTO saved_searches(id, user_email, name, query, params, frequency) VALUES(
+    uuid_generate_v4(),
+    @user_email::text,
+    @name::text,
+    @query::text,
+    @params::jsonb,
+    @frequency::text
+)
+RETURNING *;
+
+-- name: SavedSearchesByEmail :many
+SELECT s.*
+FROM saved_searches s
+WHERE s.user_email = @user_email::text
+ORDER BY s.created_at DESC;
+
+-- name: UpdateSavedSearchFrequency :one
+UPDATE saved_searches
+SET frequency = @frequency::text
+WHERE id = @saved_search_id::text
+AND user_email = @user_email::text
+RETURNING *;
+
+-- name: DeleteSavedSearch :execrows
+DELETE FROM saved_searches s
+WHERE s.id = @saved_search_id::text
+AND s.user_email = @user_email::text;
+
+-- name: DueSavedSearches :many
+SELECT s.*, NOW()::timestamp AS checked_at
+FROM saved_searches s
+WHERE (sqlc.narg(frequency)::text IS NULL OR s.frequency = sqlc.narg(frequency)::text)
+AND s.last_checked_at <= NOW() - CASE s.frequency
+    WHEN 'daily' THEN INTERVAL '1 day'
+    WHEN 'weekly' THEN INTERVAL '7 days'
+    ELSE INTERVAL '0'
+END
+ORDER BY s.last_checked_at;
+
+-- name: MarkSavedSearchChecked :exec
+UPDATE saved_searches
+SET last_checked_at = @checked_at::timestamp
+WHERE id = @saved_search_id::text;
@@ -0,0 +1,34 @@
+-- name: AddToWatchlist :exec
+INSERT INTO watchlist(user_email, listing_id) VALUES(
+    @user_email::text,
+    @listing_id::text
+)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveFromWatchlist :exec
+DELETE FROM watchlist w
+WHERE w.user_email = @user_email::text
+AND w.listing_id = @listing_id::text;
+
+-- name: IsWatching :one
+SELECT EXISTS(
+    SELECT 1
+    FROM watchlist w
+    WHERE w.user_email = @user_email::text
+    AND w.listing_id = @listing_id::text
+);
+
+-- name: WatchedListingIDs :many
+-- Narrows listing_ids to those the user has saved, so a page of cards can
+-- render its save buttons from one lookup.
+SELECT w.listing_id
+FROM watchlist w
+WHERE w.user_email = @user_email::text
+AND w.listing_id = ANY(@listing_ids::text[]);
+
+-- name: WatchedListings :many
+SELECT l.*
+FROM listing_with_image_urls l
+JOIN watchlist w ON w.listing_id = l.id
+WHERE w.user_email = @user_email::text
+ORDER BY w.created_at DESC;
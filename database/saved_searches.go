@@ -0,0 +1,25 @@
+package database
+
+const (
+	AlertFrequencyInstant = "instant"
+	AlertFrequencyDaily   = "daily"
+	AlertFrequencyWeekly  = "weekly"
+)
+
+// AlertFrequencies lists how often a saved search may alert its owner, from
+// most to least frequent.
+var AlertFrequencies = []string{
+	AlertFrequencyInstant,
+	AlertFrequencyDaily,
+	AlertFrequencyWeekly,
+}
+
+func IsAlertFrequency(frequency string) bool {
+	for _, v := range AlertFrequencies {
+		if v == frequency {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,7 +3,6 @@ module github.com/DillonEnge/jolt
 go 1.23.0
 
 require (
-	github.com/DillonEnge/seaweedfs-go-client v0.1.6
 	github.com/a-h/templ v0.3.819
 	github.com/alexedwards/scs/v2 v2.8.0
 	github.com/casdoor/casdoor-go-sdk v0.50.0
@@ -11,6 +10,8 @@ require (
 	github.com/gofrs/uuid/v5 v5.3.0
 	github.com/jackc/pgx/v5 v5.6.0
 	github.com/nats-io/nats.go v1.37.0
+	golang.org/x/image v0.25.0
+	golang.org/x/sync v0.12.0
 )
 
 require (
@@ -26,9 +27,8 @@ require (
 	golang.org/x/crypto v0.31.0 // indirect
 	golang.org/x/net v0.33.0 // indirect
 	golang.org/x/oauth2 v0.13.0 // indirect
-	golang.org/x/sync v0.10.0 // indirect
 	golang.org/x/sys v0.28.0 // indirect
-	golang.org/x/text v0.21.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/protobuf v1.31.0 // indirect
 )
@@ -0,0 +1,160 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go"
+)
+
+// SubjectListingPublished carries the ID of each listing as it goes live. It
+// wakes the matcher so instant alerts don't wait for the next sweep.
+const SubjectListingPublished = "listings.published"
+
+const (
+	sweepInterval = time.Minute
+	// matchPageSize is how many matching listings a search fetches at a
+	// time. Every page is read, so no match is left behind the checkpoint.
+	matchPageSize = 20
+	// matchLookback reaches back past a search's checkpoint for listings
+	// stamped published before it but committed after, as when publishing
+	// waits on image uploads. Matches already sent are skipped.
+	matchLookback = 15 * time.Minute
+)
+
+// Matcher checks saved searches against newly published listings and notifies
+// their owners of any matches, at each search's alert frequency.
+type Matcher struct {
+	db *pgxpool.Pool
+	nc *nats.Conn
+	mu sync.Mutex
+}
+
+func NewMatcher(db *pgxpool.Pool, nc *nats.Conn) *Matcher {
+	return &Matcher{
+		db: db,
+		nc: nc,
+	}
+}
+
+// Start sweeps due saved searches every minute, and instant searches whenever
+// a listing is published, until the returned stop func is called.
+func (m *Matcher) Start(ctx context.Context) (func(), error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	sub, err := m.nc.Subscribe(SubjectListingPublished, func(*nats.Msg) {
+		m.sweep(ctx, pgtype.Text{String: database.AlertFrequencyInstant, Valid: true})
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go func() {
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.sweep(ctx, pgtype.Text{})
+			}
+		}
+	}()
+
+	stop := func() {
+		sub.Unsubscribe()
+		cancel()
+	}
+
+	return stop, nil
+}
+
+// sweep matches every due saved search, optionally narrowed to a single
+// frequency.
+func (m *Matcher) sweep(ctx context.Context, frequency pgtype.Text) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	searches, err := database.New(m.db).DueSavedSearches(ctx, frequency)
+	if err != nil {
+		slog.Error("failed to fetch due saved searches", "err", err)
+		return
+	}
+
+	for _, search := range searches {
+		if err := m.match(ctx, search); err != nil {
+			slog.Error("failed to match saved search", "id", search.ID, "err", err)
+		}
+	}
+}
+
+// match notifies the owner of listings published since the search was last
+// checked, then moves its checkpoint forward.
+func (m *Matcher) match(ctx context.Context, search database.DueSavedSearchesRow) error {
+	var params database.SearchListingsParams
+	if err := json.Unmarshal(search.Params, &params); err != nil {
+		return err
+	}
+	params.PublishedAfter = pgtype.Timestamp{
+		Time:  search.LastCheckedAt.Time.Add(-matchLookback),
+		Valid: search.LastCheckedAt.Valid,
+	}
+	params.Sort = database.ListingSortNewest
+	params.PageSize = matchPageSize
+
+	queries, tx, err := database.NewQueries(ctx, m.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Listings published while paging push older ones onto later pages, so
+	// some may be seen twice, but none are skipped. Repeats are deduplicated
+	// by RecordNotification.
+	for offset := 0; ; offset += matchPageSize {
+		params.PageOffset = int32(offset)
+
+		listings, err := queries.SearchListings(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		for _, listing := range listings {
+			if listing.SellerEmail == search.UserEmail {
+				continue
+			}
+
+			if err := queries.RecordNotification(ctx, database.RecordNotificationParams{
+				UserEmail: search.UserEmail,
+				ListingID: listing.ID,
+				Kind:      database.NotificationKindSearch,
+				Message:   fmt.Sprintf("New match for %s: %s", search.Name, listing.Name),
+			}); err != nil {
+				return err
+			}
+		}
+
+		if len(listings) < matchPageSize {
+			break
+		}
+	}
+
+	if err := queries.MarkSavedSearchChecked(ctx, database.MarkSavedSearchCheckedParams{
+		CheckedAt:     search.CheckedAt,
+		SavedSearchID: search.ID,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
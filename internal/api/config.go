@@ -13,6 +13,10 @@ type Config struct {
 	NatsURL   string
 	Casdoor   CasdoorConfig
 	SeaweedFS SeaweedFSConfig
+	Storage   StorageConfig
+	// ImageSigningKey signs the URLs images are served from. When unset, a
+	// random key is used and URLs stop working on restart.
+	ImageSigningKey string
 }
 
 type CasdoorConfig struct {
@@ -29,6 +33,28 @@ type SeaweedFSConfig struct {
 	VolumesURL string
 }
 
+// StorageConfig selects where listing images are stored. Backend is one of
+// "seaweedfs" (the default), "local" or "s3".
+type StorageConfig struct {
+	Backend string
+	Local   LocalStorageConfig
+	S3      S3Config
+}
+
+type LocalStorageConfig struct {
+	Dir string
+	URL string
+}
+
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PublicURL       string
+}
+
 func NewConfig() *Config {
 	port, err := strconv.Atoi(os.Getenv("PORT"))
 	if err != nil {
@@ -40,6 +66,11 @@ func NewConfig() *Config {
 		natsURL = nats.DefaultURL
 	}
 
+	localStorageDir, ok := os.LookupEnv("STORAGE_LOCAL_DIR")
+	if !ok {
+		localStorageDir = "./data/blobs"
+	}
+
 	return &Config{
 		DBUrl:   os.Getenv("DATABASE_URL"),
 		Port:    port,
@@ -56,5 +87,21 @@ func NewConfig() *Config {
 			MasterURL:  os.Getenv("SEAWEEDFS_MASTER_URL"),
 			VolumesURL: os.Getenv("SEAWEEDFS_VOLUMES_URL"),
 		},
+		ImageSigningKey: os.Getenv("IMAGE_SIGNING_KEY"),
+		Storage: StorageConfig{
+			Backend: os.Getenv("STORAGE_BACKEND"),
+			Local: LocalStorageConfig{
+				Dir: localStorageDir,
+				URL: "/blobs",
+			},
+			S3: S3Config{
+				Endpoint:        os.Getenv("S3_ENDPOINT"),
+				Region:          os.Getenv("S3_REGION"),
+				Bucket:          os.Getenv("S3_BUCKET"),
+				AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+				SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+				PublicURL:       os.Getenv("S3_PUBLIC_URL"),
+			},
+		},
 	}
 }
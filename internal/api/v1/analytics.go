@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/DillonEnge/jolt/internal/auth"
+	"github.com/DillonEnge/jolt/internal/views"
+	"github.com/DillonEnge/jolt/templates"
+	"github.com/alexedwards/scs/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxAnalyticsDays caps how far back the views chart reaches, which is as far
+// back as view events are kept.
+const maxAnalyticsDays = views.RetentionDays
+
+// HandleAnalytics renders the seller dashboard, either across all of the
+// signed-in user's listings or for the one named by listing_id.
+func HandleAnalytics(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		claims, apiErr := authClient.RequireClaims(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		daysParam := r.URL.Query().Get("days")
+		if daysParam == "" {
+			daysParam = "30"
+		}
+
+		days, err := strconv.Atoi(daysParam)
+		if err != nil || days < 1 || days > maxAnalyticsDays {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("days must be between 1 and %d", maxAnalyticsDays),
+			}
+		}
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		var listingID pgtype.Text
+		if id := r.URL.Query().Get("listing_id"); id != "" {
+			if _, _, apiErr := authClient.RequireListingOwner(r.Context(), sm, queries, id); apiErr != nil {
+				return apiErr
+			}
+			listingID = pgtype.Text{String: id, Valid: true}
+		}
+
+		listings, err := queries.SellerListingStats(r.Context(), database.SellerListingStatsParams{
+			Days:        int32(days),
+			SellerEmail: claims.Email,
+		})
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		selected := listings
+		if listingID.Valid {
+			selected, err = queries.SellerListingStats(r.Context(), database.SellerListingStatsParams{
+				Days:        int32(days),
+				SellerEmail: claims.Email,
+				ListingID:   listingID,
+			})
+			if err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+		}
+
+		views, err := queries.SellerViewsByDay(r.Context(), database.SellerViewsByDayParams{
+			Days:        int32(days),
+			SellerEmail: claims.Email,
+			ListingID:   listingID,
+		})
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		templates.Analytics(templates.AnalyticsData{
+			Summary:   database.SummarizeSellerListings(selected),
+			Listings:  listings,
+			Selected:  selected,
+			Views:     views,
+			ListingID: listingID.String,
+			Days:      days,
+		}).Render(r.Context(), w)
+
+		return nil
+	}
+}
@@ -0,0 +1,562 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/DillonEnge/jolt/internal/auth"
+	"github.com/DillonEnge/jolt/templates"
+	"github.com/alexedwards/scs/v2"
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CategoryAttributeFetcher interface {
+	CategoryAttributesByCategoryID(ctx context.Context, categoryID string) ([]database.CategoryAttribute, error)
+}
+
+type CategoryFetcher interface {
+	CategoriesWithListingCounts(ctx context.Context) ([]database.CategoriesWithListingCountsRow, error)
+	CategoryAttributes(ctx context.Context) ([]database.CategoryAttribute, error)
+	CategoryBySlug(ctx context.Context, slug string) (database.Category, error)
+	SearchListings(ctx context.Context, arg database.SearchListingsParams) ([]database.ListingWithImageUrl, error)
+	WatchedListingIDsFetcher
+}
+
+// HandleCategories renders the category tree with the number of active
+// listings under each category.
+func HandleCategories(db CategoryFetcher, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		categories, err := db.CategoriesWithListingCounts(r.Context())
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		attributes, err := db.CategoryAttributes(r.Context())
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		claims, _ := authClient.GetClaims(r.Context(), sm)
+
+		templates.Categories(categories, attributes, claims).Render(r.Context(), w)
+
+		return nil
+	}
+}
+
+// HandleCategoryListings renders a category's subcategories along with the
+// active listings in it and every category beneath it.
+func HandleCategoryListings(db CategoryFetcher, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		slug := r.URL.Query().Get("slug")
+		if slug == "" {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("failed to provide slug query param"),
+			}
+		}
+
+		category, err := db.CategoryBySlug(r.Context(), slug)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusNotFound,
+					Err:    fmt.Errorf("category not found: %s", slug),
+				}
+			}
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		pageSize, pageNumber, apiErr := parsePagination(r)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		listings, err := db.SearchListings(r.Context(), database.SearchListingsParams{
+			CategoryID: pgtype.Text{String: category.ID, Valid: true},
+			Sort:       database.ListingSortNewest,
+			PageSize:   int32(pageSize),
+			PageOffset: int32((pageNumber - 1) * pageSize),
+		})
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		token := sm.GetString(r.Context(), "authToken")
+		claims, err := authClient.ParseJwtToken(token)
+		if err != nil {
+			slog.Error("failed to decode token", "err", err)
+			claims = nil
+		}
+
+		next := nextPageURL(r, pageSize, len(listings), pageNumber)
+
+		watched, err := watchedListingIDs(r.Context(), db, claims, listings)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		if pageNumber > 1 {
+			templates.ListingsPage(listings, next, claims, watched, token != "").Render(r.Context(), w)
+			return nil
+		}
+
+		categories, err := db.CategoriesWithListingCounts(r.Context())
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		templates.CategoryListings(category, categories, listings, next, claims, watched, token != "").Render(r.Context(), w)
+
+		return nil
+	}
+}
+
+// HandlePostCategory adds a category to the taxonomy. Admin only.
+func HandlePostCategory(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		claims, apiErr := authClient.RequireAdmin(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		name, slug, apiErr := parseCategoryForm(r)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		categoryID, err := uuid.NewV4()
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    fmt.Errorf("unable to create category id: %v", err),
+			}
+		}
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		parentID, apiErr := parseCategoryParent(r.Context(), queries, r.FormValue("parent_id"))
+		if apiErr != nil {
+			return apiErr
+		}
+
+		if _, err := queries.RecordCategory(r.Context(), database.RecordCategoryParams{
+			ID:           categoryID.String(),
+			ParentID:     parentID,
+			CategoryName: name,
+			Slug:         slug,
+		}); err != nil {
+			return categoryWriteError(err)
+		}
+
+		return renderCategories(w, r, queries, tx, claims)
+	}
+}
+
+// HandlePutCategory renames or moves a category. Admin only.
+func HandlePutCategory(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		claims, apiErr := authClient.RequireAdmin(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("failed to provide id query param"),
+			}
+		}
+
+		name, slug, apiErr := parseCategoryForm(r)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		parentID, apiErr := parseCategoryParent(r.Context(), queries, r.FormValue("parent_id"))
+		if apiErr != nil {
+			return apiErr
+		}
+
+		if parentID.Valid {
+			// Moving a category beneath one of its own descendants would
+			// detach that branch from the tree.
+			ancestors, err := queries.CategoryAncestorIDs(r.Context(), parentID.String)
+			if err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+			for _, v := range ancestors {
+				if v == id {
+					return &api.ApiError{
+						Status: http.StatusConflict,
+						Err:    fmt.Errorf("category %s cannot be moved beneath itself", id),
+					}
+				}
+			}
+		}
+
+		if _, err := queries.UpdateCategory(r.Context(), database.UpdateCategoryParams{
+			ParentID:     parentID,
+			CategoryName: name,
+			Slug:         slug,
+			CategoryID:   id,
+		}); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusNotFound,
+					Err:    fmt.Errorf("category not found: %s", id),
+				}
+			}
+			return categoryWriteError(err)
+		}
+
+		return renderCategories(w, r, queries, tx, claims)
+	}
+}
+
+// HandleDeleteCategory removes an empty category from the taxonomy. Admin only.
+func HandleDeleteCategory(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		claims, apiErr := authClient.RequireAdmin(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		id := r.URL.Query().Get("id")
+		if id == database.DefaultCategoryID {
+			return &api.ApiError{
+				Status: http.StatusConflict,
+				Err:    fmt.Errorf("the default category cannot be deleted"),
+			}
+		}
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		if _, err := queries.DeleteCategory(r.Context(), id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusNotFound,
+					Err:    fmt.Errorf("category not found: %s", id),
+				}
+			}
+			return categoryWriteError(err)
+		}
+
+		return renderCategories(w, r, queries, tx, claims)
+	}
+}
+
+// HandleCategoryAttributeFields renders the attribute inputs for a category
+// so the create listing form can swap them in when the category changes.
+func HandleCategoryAttributeFields(db CategoryAttributeFetcher) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		schema, err := db.CategoryAttributesByCategoryID(r.Context(), r.URL.Query().Get("category_id"))
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		templates.ListingAttributeFields(schema).Render(r.Context(), w)
+
+		return nil
+	}
+}
+
+// HandleCategoryAttributeFilters renders the attribute filter controls for the
+// category selected on the search page.
+func HandleCategoryAttributeFilters(db CategoryAttributeFetcher) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		schema, err := db.CategoryAttributesByCategoryID(r.Context(), r.URL.Query().Get("category"))
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		templates.AttributeFilters(schema).Render(r.Context(), w)
+
+		return nil
+	}
+}
+
+// HandlePostCategoryAttribute adds an attribute to a category's schema. Admin
+// only.
+func HandlePostCategoryAttribute(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		claims, apiErr := authClient.RequireAdmin(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		name := database.AttributeName(r.FormValue("attribute_name"))
+		if name == "" {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("attribute name is required"),
+			}
+		}
+
+		kind := r.FormValue("kind")
+		if !database.IsAttributeKind(kind) {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("invalid attribute kind: %s", kind),
+			}
+		}
+
+		options := []string{}
+		for _, v := range strings.Split(r.FormValue("options"), ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				options = append(options, v)
+			}
+		}
+		if kind == database.AttributeKindEnum && len(options) == 0 {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("enum attributes need at least one option"),
+			}
+		}
+
+		attributeID, err := uuid.NewV4()
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    fmt.Errorf("unable to create attribute id: %v", err),
+			}
+		}
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		if _, err := queries.RecordCategoryAttribute(r.Context(), database.RecordCategoryAttributeParams{
+			ID:            attributeID.String(),
+			CategoryID:    r.FormValue("category_id"),
+			AttributeName: name,
+			Kind:          kind,
+			Options:       options,
+			Required:      r.FormValue("required") == "on",
+		}); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return &api.ApiError{
+					Status: http.StatusConflict,
+					Err:    fmt.Errorf("category already has a %s attribute", name),
+				}
+			}
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return &api.ApiError{
+					Status: http.StatusBadRequest,
+					Err:    fmt.Errorf("category not found: %s", r.FormValue("category_id")),
+				}
+			}
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		return renderCategories(w, r, queries, tx, claims)
+	}
+}
+
+// HandleDeleteCategoryAttribute removes an attribute from a category's
+// schema. Values already stored on listings are left in place. Admin only.
+func HandleDeleteCategoryAttribute(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		claims, apiErr := authClient.RequireAdmin(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		id := r.URL.Query().Get("id")
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		if _, err := queries.DeleteCategoryAttribute(r.Context(), id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusNotFound,
+					Err:    fmt.Errorf("attribute not found: %s", id),
+				}
+			}
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		return renderCategories(w, r, queries, tx, claims)
+	}
+}
+
+// parseCategoryForm reads the category name and slug, deriving the
+// slug from the name when none is given.
+func parseCategoryForm(r *http.Request) (string, string, *api.ApiError) {
+	name := strings.TrimSpace(r.FormValue("category_name"))
+	if name == "" {
+		return "", "", &api.ApiError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("category name is required"),
+		}
+	}
+
+	slug := database.CategorySlug(r.FormValue("slug"))
+	if slug == "" {
+		slug = database.CategorySlug(name)
+	}
+	if slug == "" {
+		return "", "", &api.ApiError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("unable to derive a slug from %q", name),
+		}
+	}
+
+	return name, slug, nil
+}
+
+func parseCategoryParent(ctx context.Context, queries *database.Queries, parentID string) (pgtype.Text, *api.ApiError) {
+	if parentID == "" {
+		return pgtype.Text{}, nil
+	}
+
+	if _, err := queries.CategoryByID(ctx, parentID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.Text{}, &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("parent category not found: %s", parentID),
+			}
+		}
+		return pgtype.Text{}, &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	return pgtype.Text{String: parentID, Valid: true}, nil
+}
+
+// categoryWriteError maps constraint violations on the categories table to
+// client errors.
+func categoryWriteError(err error) *api.ApiError {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return &api.ApiError{
+				Status: http.StatusConflict,
+				Err:    fmt.Errorf("a category with that slug already exists"),
+			}
+		case "23503":
+			return &api.ApiError{
+				Status: http.StatusConflict,
+				Err:    fmt.Errorf("category still has subcategories or listings"),
+			}
+		}
+	}
+
+	return &api.ApiError{
+		Status: http.StatusInternalServerError,
+		Err:    err,
+	}
+}
+
+func renderCategories(w http.ResponseWriter, r *http.Request, queries *database.Queries, tx pgx.Tx, claims *casdoorsdk.Claims) *api.ApiError {
+	categories, err := queries.CategoriesWithListingCounts(r.Context())
+	if err != nil {
+		return &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	attributes, err := queries.CategoryAttributes(r.Context())
+	if err != nil {
+		return &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		return &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	templates.Categories(categories, attributes, claims).Render(r.Context(), w)
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"github.com/DillonEnge/jolt/internal/auth"
 	"github.com/DillonEnge/jolt/templates"
 	"github.com/alexedwards/scs/v2"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -23,24 +25,31 @@ func HandleChat(db *pgxpool.Pool, sm *scs.SessionManager, authClient *auth.Clien
 			}
 		}
 
-		claims, err := authClient.GetClaims(r.Context(), sm)
+		queries, tx, err := database.NewQueries(r.Context(), db)
 		if err != nil {
 			return &api.ApiError{
-				Status: http.StatusUnauthorized,
+				Status: http.StatusInternalServerError,
 				Err:    err,
 			}
 		}
+		defer tx.Rollback(r.Context())
+
+		claims, negotiation, apiErr := authClient.RequireNegotiationMember(r.Context(), sm, queries, negotiationID)
+		if apiErr != nil {
+			return apiErr
+		}
 
-		queries, tx, err := database.NewQueries(r.Context(), db)
+		messages, err := queries.MessagesByNegotiationID(r.Context(), negotiationID)
 		if err != nil {
 			return &api.ApiError{
 				Status: http.StatusInternalServerError,
 				Err:    err,
 			}
 		}
-		defer tx.Rollback(r.Context())
 
-		messages, err := queries.MessagesByNegotiationID(r.Context(), negotiationID)
+		slog.Info("messages", "messages", messages)
+
+		offers, err := queries.OffersByNegotiationID(r.Context(), negotiationID)
 		if err != nil {
 			return &api.ApiError{
 				Status: http.StatusInternalServerError,
@@ -48,9 +57,31 @@ func HandleChat(db *pgxpool.Pool, sm *scs.SessionManager, authClient *auth.Clien
 			}
 		}
 
-		slog.Info("messages", "messages", messages)
+		listing, err := queries.ListingByID(r.Context(), negotiation.ListingID)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		// The first revision recorded after the negotiation opened holds the
+		// listing as the buyer originally saw it.
+		var revision *database.ListingRevision
+		rev, err := queries.FirstListingRevisionSince(r.Context(), database.FirstListingRevisionSinceParams{
+			ListingID: listing.ID,
+			Since:     negotiation.CreatedAt,
+		})
+		if err == nil {
+			revision = &rev
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
 
-		templates.Chat(messages, negotiationID, claims).Render(r.Context(), w)
+		templates.Chat(messages, offers, negotiation, listing, revision, claims).Render(r.Context(), w)
 
 		return nil
 	}
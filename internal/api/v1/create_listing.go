@@ -1,26 +1,36 @@
 package v1
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/DillonEnge/jolt/database"
 	"github.com/DillonEnge/jolt/internal/api"
 	"github.com/DillonEnge/jolt/internal/auth"
 	"github.com/DillonEnge/jolt/templates"
 	"github.com/alexedwards/scs/v2"
 )
 
-func HandleCreateListing(sm *scs.SessionManager, authClient *auth.Client) api.HandlerFuncWithError {
+type CategoryLister interface {
+	CategoriesWithListingCounts(ctx context.Context) ([]database.CategoriesWithListingCountsRow, error)
+}
+
+func HandleCreateListing(db CategoryLister, sm *scs.SessionManager, authClient *auth.Client) api.HandlerFuncWithError {
 	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
-		token := sm.GetString(r.Context(), "authToken")
+		claims, apiErr := authClient.RequireClaims(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
 
-		claims, err := authClient.ParseJwtToken(token)
+		categories, err := db.CategoriesWithListingCounts(r.Context())
 		if err != nil {
 			return &api.ApiError{
 				Status: http.StatusInternalServerError,
 				Err:    err,
 			}
 		}
-		templates.CreateListing(claims).Render(r.Context(), w)
+
+		templates.CreateListing(claims, categories).Render(r.Context(), w)
 
 		return nil
 	}
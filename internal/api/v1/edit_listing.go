@@ -0,0 +1,270 @@
+package v1
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/DillonEnge/jolt/internal/auth"
+	"github.com/DillonEnge/jolt/internal/storage"
+	"github.com/DillonEnge/jolt/templates"
+	"github.com/alexedwards/scs/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func HandleEditListing(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		id := r.URL.Query().Get("id")
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		_, listing, apiErr := authClient.RequireListingOwner(r.Context(), sm, queries, id)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		if !database.CanEditListing(listing.Status) {
+			return &api.ApiError{
+				Status: http.StatusConflict,
+				Err:    fmt.Errorf("%s listings cannot be edited", listing.Status),
+			}
+		}
+
+		revisions, err := queries.ListingRevisionsByListingID(r.Context(), listing.ID)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		templates.EditListing(listing, revisions).Render(r.Context(), w)
+
+		return nil
+	}
+}
+
+// HandlePutListing updates a listing's details and images, snapshotting the
+// previous version into listing_revisions first.
+func HandlePutListing(db *pgxpool.Pool, store storage.Store, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		id := r.URL.Query().Get("id")
+
+		// Authorize before reading the body, so only the seller can make the
+		// server buffer an upload. The listing is checked again below, inside
+		// the transaction.
+		_, listing, apiErr := authClient.RequireListingOwner(r.Context(), sm, database.New(db), id)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		if apiErr := parseUploadForm(w, r); apiErr != nil {
+			return apiErr
+		}
+
+		listingName := r.FormValue("listing_name")
+		description := r.FormValue("description")
+
+		price, err := strconv.ParseFloat(r.FormValue("price"), 32)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("invalid price format: %v", err),
+			}
+		}
+
+		removedImages := r.MultipartForm.Value["remove_images"]
+		newFiles := r.MultipartForm.File["images"]
+		priceCents := int32(float32(price) * 100)
+
+		// Store new images before the transaction opens, so the request
+		// doesn't hold a connection, or the listing's row lock, while
+		// uploading. The edit is checked against the listing as it stands
+		// first, so a refused edit doesn't upload anything.
+		if apiErr := checkListingEdit(listing, removedImages, newFiles); apiErr != nil {
+			return apiErr
+		}
+
+		pending := database.New(db)
+		uploaded, apiErr := uploadImages(r.Context(), pending, store, newFiles)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		committed := false
+		defer func() {
+			if !committed {
+				rollbackUploads(r.Context(), pending, store, uploaded.urls())
+			}
+		}()
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		claims, listing, apiErr := authClient.RequireListingOwner(r.Context(), sm, queries, id)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		if apiErr := checkListingEdit(listing, removedImages, newFiles); apiErr != nil {
+			return apiErr
+		}
+
+		kept := keptImageOrder(listing.ImageIds, listing.ImageIds, removedImages)
+		order := keptImageOrder(listing.ImageIds, r.MultipartForm.Value["image_order"], removedImages)
+		reordered := !slices.Equal(order, kept)
+
+		changed := listingName != listing.Name ||
+			description != listing.Description.String ||
+			priceCents != listing.Price ||
+			len(removedImages) > 0 ||
+			len(newFiles) > 0 ||
+			reordered
+
+		if !changed {
+			templates.IndividualListing(listing, claims, false, true).Render(r.Context(), w)
+			return nil
+		}
+
+		if _, err := queries.RecordListingRevision(r.Context(), listing.ID); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		if _, err := queries.UpdateListing(r.Context(), database.UpdateListingParams{
+			ListingName: listingName,
+			Description: description,
+			Price:       priceCents,
+			ListingID:   listing.ID,
+		}); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		if priceCents < listing.Price {
+			if apiErr := notifyWatchers(r.Context(), queries, listing, database.NotificationKindPriceDrop, priceCents); apiErr != nil {
+				return apiErr
+			}
+		}
+
+		if len(removedImages) > 0 {
+			if err := queries.DeleteListingImages(r.Context(), database.DeleteListingImagesParams{
+				ListingID:    listing.ID,
+				ImageIDArray: removedImages,
+			}); err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+		}
+
+		if reordered {
+			if err := queries.ReorderListingImages(r.Context(), database.ReorderListingImagesParams{
+				ImageIDArray: order,
+				ListingID:    listing.ID,
+			}); err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+		}
+
+		// New images go after the existing ones, so they never displace the
+		// cover.
+		if _, err := queries.RecordListingImages(r.Context(), uploaded.recordParams(listing.ID)); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		if apiErr := checkListingSize(r.Context(), queries, listing.ID); apiErr != nil {
+			return apiErr
+		}
+
+		if err := queries.CommitPendingUploads(r.Context(), uploaded.urls()); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		listing, err = queries.ListingByID(r.Context(), listing.ID)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		if err := tx.Commit(r.Context()); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		committed = true
+
+		w.WriteHeader(http.StatusOK)
+		templates.IndividualListing(listing, claims, false, true).Render(r.Context(), w)
+
+		return nil
+	}
+}
+
+// checkListingEdit refuses edits to listings that can no longer be edited, and
+// new images the listing has no room for.
+func checkListingEdit(listing database.ListingWithImageUrl, removedImages []string, newFiles []*multipart.FileHeader) *api.ApiError {
+	if !database.CanEditListing(listing.Status) {
+		return &api.ApiError{
+			Status: http.StatusConflict,
+			Err:    fmt.Errorf("%s listings cannot be edited", listing.Status),
+		}
+	}
+
+	kept := keptImageOrder(listing.ImageIds, listing.ImageIds, removedImages)
+
+	return validateUploads(newFiles, len(kept))
+}
+
+// keptImageOrder returns the IDs of the listing images that survive removal,
+// in the order the seller arranged them. Images missing from order, such as
+// ones added since the form was rendered, follow in their current order.
+func keptImageOrder(current []string, order []string, removed []string) []string {
+	kept := make([]string, 0, len(current))
+	for _, id := range order {
+		if slices.Contains(current, id) && !slices.Contains(removed, id) && !slices.Contains(kept, id) {
+			kept = append(kept, id)
+		}
+	}
+	for _, id := range current {
+		if !slices.Contains(removed, id) && !slices.Contains(kept, id) {
+			kept = append(kept, id)
+		}
+	}
+
+	return kept
+}
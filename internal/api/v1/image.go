@@ -0,0 +1,129 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/DillonEnge/jolt/internal/images"
+	"github.com/DillonEnge/jolt/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+type ListingImageFetcher interface {
+	ListingImageByID(ctx context.Context, imageID string) (database.ListingImage, error)
+}
+
+// HandleImage serves a rendition of a listing image from a signed URL issued
+// by images.SignedURL or images.PublicURL. Blobs never change once stored, so
+// responses are cacheable until the URL expires, and are streamed from the
+// store rather than read into memory.
+func HandleImage(db ListingImageFetcher, store storage.Store) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		id := r.PathValue("id")
+		variant := r.PathValue("variant")
+
+		if !slices.ContainsFunc(images.Variants, func(v images.Variant) bool { return v.Name == variant }) {
+			return &api.ApiError{
+				Status: http.StatusNotFound,
+				Err:    fmt.Errorf("unknown image variant: %s", variant),
+			}
+		}
+
+		expiresAt, err := images.VerifySignedURL(id, variant, r.URL.Query())
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusForbidden,
+				Err:    err,
+			}
+		}
+
+		image, err := db.ListingImageByID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusNotFound,
+					Err:    fmt.Errorf("image not found: %s", id),
+				}
+			}
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		etag := fmt.Sprintf(`"%s-%s"`, image.ID, variant)
+		maxAge := int(time.Until(expiresAt).Seconds())
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", maxAge))
+		w.Header().Set("ETag", etag)
+
+		// Answer revalidation without opening the blob.
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+
+		blob, err := store.Get(r.Context(), variantBlobURL(image, variant))
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return &api.ApiError{
+					Status: http.StatusNotFound,
+					Err:    fmt.Errorf("image not found: %s", id),
+				}
+			}
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer blob.Close()
+
+		// A known type spares ServeContent sniffing, which would read the
+		// start of the blob and seek back.
+		if blob.ContentType != "" {
+			w.Header().Set("Content-Type", blob.ContentType)
+		}
+
+		// ServeContent answers conditional and range requests, seeking the
+		// blob so only the requested range is read from the store.
+		http.ServeContent(w, r, "", time.Time{}, blob)
+
+		return nil
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 requires for If-None-Match.
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// variantBlobURL returns the stored URL of an image's rendition, falling back
+// to the full-size image for uploads that predate variants.
+func variantBlobURL(image database.ListingImage, variant string) string {
+	switch variant {
+	case images.VariantThumbnail:
+		if image.ThumbnailUrl.Valid {
+			return image.ThumbnailUrl.String
+		}
+	case images.VariantCard:
+		if image.CardUrl.Valid {
+			return image.CardUrl.String
+		}
+	}
+
+	return image.ImageUrl
+}
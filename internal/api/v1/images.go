@@ -0,0 +1,346 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/DillonEnge/jolt/internal/images"
+	"github.com/DillonEnge/jolt/internal/storage"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// uploadWorkers bounds how many files a request processes and stores at
+	// once. Decoding is further limited process-wide by images.Process.
+	uploadWorkers = 4
+	// uploadTimeout bounds how long a request may spend storing its images.
+	uploadTimeout = time.Minute
+)
+
+// PendingUploadTracker tracks a blob before it is stored, so it can be
+// collected if the listing referencing it is never committed. It must not run
+// in the request's transaction, or a rollback would lose the record too.
+type PendingUploadTracker interface {
+	RecordPendingUploads(ctx context.Context, urlArray []string) error
+	DeletePendingUpload(ctx context.Context, url string) error
+}
+
+// uploadedImages holds the public URLs of each stored rendition, the width of
+// the full one and the renditions' total size. The slices are parallel, with
+// one entry per uploaded file in upload order.
+type uploadedImages struct {
+	Full      []string
+	Thumbnail []string
+	Card      []string
+	Widths    []int32
+	Sizes     []int64
+}
+
+func (u uploadedImages) recordParams(listingID string) database.RecordListingImagesParams {
+	return database.RecordListingImagesParams{
+		ListingID:         listingID,
+		ImageUrlArray:     u.Full,
+		ThumbnailUrlArray: u.Thumbnail,
+		CardUrlArray:      u.Card,
+		WidthArray:        u.Widths,
+		SizeArray:         u.Sizes,
+	}
+}
+
+// urls returns every stored rendition, for committing the pending uploads
+// alongside the listing images that reference them.
+func (u uploadedImages) urls() []string {
+	return slices.Concat(u.Full, u.Thumbnail, u.Card)
+}
+
+// validateUploads checks every uploaded file before any is stored, so a bad
+// file doesn't leave the others behind. existing is the number of images the
+// listing keeps alongside the new ones.
+func validateUploads(files []*multipart.FileHeader, existing int) *api.ApiError {
+	if existing+len(files) > images.MaxListingImages {
+		return &api.ApiError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("a listing can have at most %d images", images.MaxListingImages),
+		}
+	}
+
+	var total int64
+	for _, fileHeader := range files {
+		total += fileHeader.Size
+		if total > images.MaxUploadSize {
+			return &api.ApiError{
+				Status: http.StatusRequestEntityTooLarge,
+				Err:    fmt.Errorf("images must total at most %d MB per upload", images.MaxUploadSize>>20),
+			}
+		}
+
+		f, err := fileHeader.Open()
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    fmt.Errorf("unable to open image fileHeader: %v", err),
+			}
+		}
+
+		err = images.Validate(f, fileHeader.Size)
+		f.Close()
+		if err != nil {
+			return &api.ApiError{
+				Status: uploadErrorStatus(err),
+				Err:    fmt.Errorf("%s: %v", fileHeader.Filename, err),
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkListingSize rejects a change that leaves a listing's stored images
+// over the size limit. It runs in the change's transaction, after the images
+// are recorded, so concurrent edits can't each pass on their own.
+func checkListingSize(ctx context.Context, queries *database.Queries, listingID string) *api.ApiError {
+	size, err := queries.ListingImagesSize(ctx, listingID)
+	if err != nil {
+		return &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	if size > images.MaxListingSize {
+		return &api.ApiError{
+			Status: http.StatusRequestEntityTooLarge,
+			Err:    fmt.Errorf("a listing's images can take up at most %d MB", images.MaxListingSize>>20),
+		}
+	}
+
+	return nil
+}
+
+func uploadErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, images.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, images.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, images.ErrInvalidImage):
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
+
+// parseUploadForm parses a multipart listing form, refusing bodies larger
+// than the upload limit plus room for the other fields.
+func parseUploadForm(w http.ResponseWriter, r *http.Request) *api.ApiError {
+	r.Body = http.MaxBytesReader(w, r.Body, images.MaxUploadSize+(1<<20))
+
+	// Parse multipart form with 10MB max memory
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return &api.ApiError{
+				Status: http.StatusRequestEntityTooLarge,
+				Err:    fmt.Errorf("images must total at most %d MB per upload", images.MaxUploadSize>>20),
+			}
+		}
+		return &api.ApiError{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		}
+	}
+
+	return nil
+}
+
+// uploadImages processes each uploaded file into its resized, metadata-free
+// variants and stores them, a few files at a time. It is all or nothing: if
+// any file fails, or the upload deadline passes, every blob already stored is
+// deleted again. Otherwise each stored blob is recorded as pending until the
+// caller commits it, or rolls it back with rollbackUploads.
+//
+// Every blob is reserved before any is stored, so they are all recorded as
+// pending in a single query rather than a database round trip per blob.
+func uploadImages(ctx context.Context, pending PendingUploadTracker, store storage.Store, files []*multipart.FileHeader) (uploadedImages, *api.ApiError) {
+	uploaded := uploadedImages{
+		Full:      []string{},
+		Thumbnail: []string{},
+		Card:      []string{},
+		Widths:    []int32{},
+		Sizes:     []int64{},
+	}
+	if len(files) == 0 {
+		return uploaded, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uploadTimeout)
+	defer cancel()
+
+	// reserved holds each file's renditions, by index, so the result keeps
+	// upload order however the workers finish.
+	reserved := make([][]reservedVariant, len(files))
+
+	slog.Info("Processing images", "count", len(files))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(uploadWorkers)
+	for i, fileHeader := range files {
+		g.Go(func() error {
+			slog.Info("Image file", "index", i, "filename", fileHeader.Filename, "size", fileHeader.Size)
+			f, err := fileHeader.Open()
+			if err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    fmt.Errorf("unable to open image fileHeader: %v", err),
+				}
+			}
+
+			variants, err := images.Process(gctx, f)
+			f.Close()
+			if err != nil {
+				return &api.ApiError{
+					Status: http.StatusBadRequest,
+					Err:    fmt.Errorf("%s is not a supported image: %v", fileHeader.Filename, err),
+				}
+			}
+
+			name := strings.TrimSuffix(fileHeader.Filename, path.Ext(fileHeader.Filename))
+			for _, v := range variants {
+				fileName := fmt.Sprintf("%s_%s.jpg", name, v.Variant.Name)
+				imageURL, err := store.Reserve(gctx, fileName)
+				if err != nil {
+					return &api.ApiError{
+						Status: http.StatusInternalServerError,
+						Err:    fmt.Errorf("failed to store image: %v", err),
+					}
+				}
+
+				reserved[i] = append(reserved[i], reservedVariant{Processed: v, URL: imageURL, FileName: fileName})
+			}
+
+			return nil
+		})
+	}
+
+	if apiErr := waitForUploads(ctx, g); apiErr != nil {
+		return uploadedImages{}, apiErr
+	}
+
+	var all []string
+	for _, variants := range reserved {
+		for _, v := range variants {
+			all = append(all, v.URL)
+		}
+	}
+
+	// Track the blobs before storing them, so they are collected even if the
+	// request dies partway through.
+	if err := pending.RecordPendingUploads(ctx, all); err != nil {
+		return uploadedImages{}, &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	g, gctx = errgroup.WithContext(ctx)
+	g.SetLimit(uploadWorkers)
+	for _, variants := range reserved {
+		for _, v := range variants {
+			g.Go(func() error {
+				if err := store.Put(gctx, v.URL, v.FileName, v.Data); err != nil {
+					return &api.ApiError{
+						Status: http.StatusInternalServerError,
+						Err:    fmt.Errorf("failed to store image: %v", err),
+					}
+				}
+
+				return nil
+			})
+		}
+	}
+
+	if apiErr := waitForUploads(ctx, g); apiErr != nil {
+		rollbackUploads(ctx, pending, store, all)
+		return uploadedImages{}, apiErr
+	}
+
+	for _, variants := range reserved {
+		var size int64
+		for _, v := range variants {
+			size += int64(len(v.Data))
+
+			switch v.Variant.Name {
+			case images.VariantThumbnail:
+				uploaded.Thumbnail = append(uploaded.Thumbnail, v.URL)
+			case images.VariantCard:
+				uploaded.Card = append(uploaded.Card, v.URL)
+			case images.VariantFull:
+				uploaded.Full = append(uploaded.Full, v.URL)
+				uploaded.Widths = append(uploaded.Widths, int32(v.Width))
+			}
+		}
+		uploaded.Sizes = append(uploaded.Sizes, size)
+	}
+
+	return uploaded, nil
+}
+
+// reservedVariant is a processed rendition and the URL reserved for it.
+type reservedVariant struct {
+	images.Processed
+	URL      string
+	FileName string
+}
+
+// waitForUploads waits for an upload stage's workers, turning their first
+// error, or the upload deadline passing, into an API error.
+func waitForUploads(ctx context.Context, g *errgroup.Group) *api.ApiError {
+	err := g.Wait()
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &api.ApiError{
+			Status: http.StatusGatewayTimeout,
+			Err:    errors.New("uploading images took too long, please try again"),
+		}
+	}
+
+	var apiErr *api.ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return &api.ApiError{
+		Status: http.StatusInternalServerError,
+		Err:    err,
+	}
+}
+
+// rollbackUploads deletes blobs that won't be committed. It runs even once
+// the request is cancelled; blobs it fails to delete stay pending for the
+// collector.
+func rollbackUploads(ctx context.Context, pending PendingUploadTracker, store storage.Store, urls []string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), uploadTimeout)
+	defer cancel()
+
+	for _, url := range urls {
+		if err := store.Delete(ctx, url); err != nil {
+			slog.Error("failed to roll back upload", "url", url, "err", err)
+			continue
+		}
+
+		if err := pending.DeletePendingUpload(ctx, url); err != nil {
+			slog.Error("failed to clear rolled back upload", "url", url, "err", err)
+		}
+	}
+}
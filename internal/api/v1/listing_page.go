@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/DillonEnge/jolt/internal/auth"
+	"github.com/DillonEnge/jolt/internal/images"
+	"github.com/DillonEnge/jolt/templates"
+	"github.com/alexedwards/scs/v2"
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/jackc/pgx/v5"
+)
+
+type ListingPageFetcher interface {
+	auth.ListingFetcher
+	IsWatching(ctx context.Context, arg database.IsWatchingParams) (bool, error)
+}
+
+// HandleListingPage renders the shareable permalink for a listing as a full
+// page, so it works when opened directly or unfurled by a link preview.
+func HandleListingPage(db ListingPageFetcher, authClient *auth.Client, sm *scs.SessionManager, config *api.Config) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		id := r.PathValue("id")
+
+		listing, err := db.ListingByID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusNotFound,
+					Err:    fmt.Errorf("listing not found: %s", id),
+				}
+			}
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		var claims *casdoorsdk.Claims
+		if token := sm.GetString(r.Context(), "authToken"); token != "" {
+			claims, err = authClient.ParseJwtToken(token)
+			if err != nil {
+				slog.Error("failed to decode token", "err", err)
+				claims = nil
+			}
+		}
+
+		// Drafts are private to their seller.
+		if listing.Status == database.ListingStatusDraft && (claims == nil || claims.Email != listing.SellerEmail) {
+			return &api.ApiError{
+				Status: http.StatusNotFound,
+				Err:    fmt.Errorf("listing not found: %s", id),
+			}
+		}
+
+		var watching bool
+		if claims != nil {
+			watching, err = db.IsWatching(r.Context(), database.IsWatchingParams{
+				UserEmail: claims.Email,
+				ListingID: listing.ID,
+			})
+			if err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+		}
+
+		var imageURL string
+		if len(listing.ImageIds) > 0 {
+			imageURL = absoluteURL(r, images.PublicURL(listing.ImageIds[0], images.VariantFull))
+		}
+
+		w.WriteHeader(http.StatusOK)
+		templates.ListingPage(listing, claims, watching, config, absoluteURL(r, r.URL.Path), imageURL).Render(r.Context(), w)
+
+		return nil
+	}
+}
+
+// absoluteURL resolves path against the host the request was made to,
+// honouring the scheme set by a TLS-terminating proxy.
+func absoluteURL(r *http.Request, path string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+
+	return fmt.Sprintf("%s://%s%s", scheme, r.Host, path)
+}
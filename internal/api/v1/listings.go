@@ -3,42 +3,55 @@ package v1
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/alerts"
 	"github.com/DillonEnge/jolt/internal/api"
 	"github.com/DillonEnge/jolt/internal/auth"
+	"github.com/DillonEnge/jolt/internal/storage"
 	"github.com/DillonEnge/jolt/templates"
-	"github.com/DillonEnge/seaweedfs-go-client"
 	"github.com/alexedwards/scs/v2"
 	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go"
 )
 
-type ListingFetcher interface {
-	ListingByID(ctx context.Context, listingID string) (database.ListingWithImageUrl, error)
-	ListingsByLikeName(ctx context.Context, listingName string) ([]database.ListingWithImageUrl, error)
+type PostalCodeLocator interface {
+	PostalCodeByCode(ctx context.Context, postalCode string) (database.PostalCode, error)
 }
 
-type ListingViewsUpserter interface {
-	UpsertListingViews(ctx context.Context, listingID string) (database.ListingView, error)
+type ListingFetcher interface {
+	PostalCodeLocator
+	WatchedListingIDsFetcher
+	ListingByID(ctx context.Context, listingID string) (database.ListingWithImageUrl, error)
+	SearchListings(ctx context.Context, arg database.SearchListingsParams) ([]database.ListingWithImageUrl, error)
+	SuggestSearchTerm(ctx context.Context, term string) (string, error)
 }
 
-type ListingRecorderFetcher interface {
-	RecordListing(ctx context.Context, arg database.RecordListingParams) (database.Listing, error)
-	RecordListingImages(ctx context.Context, arg database.RecordListingImagesParams) ([]database.ListingImage, error)
-	ListingByID(ctx context.Context, listingID string) (database.ListingWithImageUrl, error)
+type TrendingListingsFetcher interface {
+	PostalCodeLocator
+	WatchedListingIDsFetcher
+	TrendingListings(ctx context.Context, arg database.TrendingListingsParams) ([]database.ListingWithImageUrl, error)
 }
 
-type ListingsByViewsFetcher interface {
-	ListingsByViews(ctx context.Context, arg database.ListingsByViewsParams) ([]database.ListingWithImageUrl, error)
+// statusNotificationKinds maps the listing statuses watchers hear about to
+// the notification sent for them.
+var statusNotificationKinds = map[string]string{
+	database.ListingStatusReserved: database.NotificationKindReserved,
+	database.ListingStatusSold:     database.NotificationKindSold,
+	database.ListingStatusArchived: database.NotificationKindDeleted,
 }
 
 type RecordListingParams struct {
-	SellerEmail string  `json:"seller_email"`
 	ListingName string  `json:"listing_name"`
 	Description string  `json:"description"`
 	Price       float32 `json:"price,string"`
@@ -46,7 +59,8 @@ type RecordListingParams struct {
 
 func HandleListings(db ListingFetcher, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
 	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
-		title := r.URL.Query().Get("title")
+		query := r.URL.Query()
+		title := query.Get("title")
 
 		if title == "" {
 			return &api.ApiError{
@@ -55,14 +69,21 @@ func HandleListings(db ListingFetcher, authClient *auth.Client, sm *scs.SessionM
 			}
 		}
 
-		name := r.URL.Query().Get("name")
+		pageSize, pageNumber, apiErr := parsePagination(r)
+		if apiErr != nil {
+			return apiErr
+		}
 
-		if name == "" {
-			templates.NoResults().Render(r.Context(), w)
-			return nil
+		params, apiErr := parseSearchParams(r.Context(), db, query)
+		if apiErr != nil {
+			return apiErr
 		}
+		params.PageSize = int32(pageSize)
+		params.PageOffset = int32((pageNumber - 1) * pageSize)
+
+		name := query.Get("name")
 
-		listings, err := db.ListingsByLikeName(r.Context(), name)
+		listings, err := db.SearchListings(r.Context(), params)
 		if err != nil {
 			return &api.ApiError{
 				Status: http.StatusInternalServerError,
@@ -70,58 +91,392 @@ func HandleListings(db ListingFetcher, authClient *auth.Client, sm *scs.SessionM
 			}
 		}
 
+		token := sm.GetString(r.Context(), "authToken")
+		claims, err := authClient.ParseJwtToken(token)
+		if err != nil {
+			slog.Error("failed to decode token", "err", err)
+			claims = nil
+		}
+
+		// Later pages only append cards to the results already on screen.
+		if pageNumber > 1 {
+			watched, err := watchedListingIDs(r.Context(), db, claims, listings)
+			if err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+
+			w.WriteHeader(http.StatusOK)
+			templates.ListingsPage(listings, nextPageURL(r, pageSize, len(listings), pageNumber), claims, watched, token != "").Render(r.Context(), w)
+			return nil
+		}
+
+		if len(listings) == 0 && name != "" {
+			// Fall back to typo-tolerant matching, keeping the other filters,
+			// and offer a corrected query.
+			params.SearchQuery = ""
+			params.SimilarName = name
+			listings, err = db.SearchListings(r.Context(), params)
+			if err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+
+			suggestion, err := suggestSearch(r.Context(), db, name)
+			if err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+
+			watched, err := watchedListingIDs(r.Context(), db, claims, listings)
+			if err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+
+			w.WriteHeader(http.StatusOK)
+			templates.FuzzySearchResults(title, listings, suggestion, claims, watched, token != "").Render(r.Context(), w)
+			return nil
+		}
+
 		if len(listings) == 0 {
 			templates.NoResults().Render(r.Context(), w)
 			return nil
 		}
 
-		token := sm.GetString(r.Context(), "authToken")
-		claims, err := authClient.ParseJwtToken(token)
+		watched, err := watchedListingIDs(r.Context(), db, claims, listings)
 		if err != nil {
-			slog.Error("failed to decode token", "err", err)
-			claims = nil
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
 		}
 
 		w.WriteHeader(http.StatusOK)
-		templates.Listings(title, listings, claims, token != "").Render(r.Context(), w)
+		templates.SearchResults(title, listings, nextPageURL(r, pageSize, len(listings), pageNumber), claims, watched, token != "").Render(r.Context(), w)
 
 		return nil
 	}
 }
 
-func HandlePopularListings(db ListingsByViewsFetcher, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
-	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
-		pageSizeParam := r.URL.Query().Get("page_size")
+// parseSearchParams reads the search filters shared by the listings search
+// and saved searches. Paging is left to the caller.
+func parseSearchParams(ctx context.Context, db PostalCodeLocator, query url.Values) (database.SearchListingsParams, *api.ApiError) {
+	minPrice, apiErr := parsePriceFilter(query.Get("min_price"))
+	if apiErr != nil {
+		return database.SearchListingsParams{}, apiErr
+	}
+
+	maxPrice, apiErr := parsePriceFilter(query.Get("max_price"))
+	if apiErr != nil {
+		return database.SearchListingsParams{}, apiErr
+	}
 
-		if pageSizeParam == "" {
-			pageSizeParam = "10"
+	sort := query.Get("sort")
+	if sort == "" {
+		sort = database.ListingSortRelevance
+	}
+	if !database.IsListingSort(sort) {
+		return database.SearchListingsParams{}, &api.ApiError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("invalid sort query param: %s", sort),
 		}
+	}
 
-		pageSize, err := strconv.Atoi(pageSizeParam)
-		if err != nil {
-			return &api.ApiError{
-				Status: http.StatusInternalServerError,
-				Err:    err,
+	attributeFilter, err := parseAttributeFilter(query)
+	if err != nil {
+		return database.SearchListingsParams{}, &api.ApiError{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		}
+	}
+
+	originLatitude, originLongitude, apiErr := parseLocation(ctx, db, query.Get("latitude"), query.Get("longitude"), query.Get("postal_code"))
+	if apiErr != nil {
+		return database.SearchListingsParams{}, apiErr
+	}
+
+	radius, apiErr := parseRadius(query.Get("radius_km"), originLatitude.Valid)
+	if apiErr != nil {
+		return database.SearchListingsParams{}, apiErr
+	}
+
+	if sort == database.ListingSortDistance && !originLatitude.Valid {
+		return database.SearchListingsParams{}, &api.ApiError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("sorting by distance requires a location"),
+		}
+	}
+
+	return database.SearchListingsParams{
+		SearchQuery:     database.ListingSearchQuery(query.Get("name")),
+		MinPrice:        minPrice,
+		MaxPrice:        maxPrice,
+		HasPhotos:       query.Get("has_photos") == "on",
+		SellerEmail:     strings.TrimSpace(query.Get("seller")),
+		CategoryID:      parseCategoryFilter(query),
+		Attributes:      attributeFilter,
+		RadiusKm:        radius,
+		OriginLatitude:  originLatitude,
+		OriginLongitude: originLongitude,
+		Sort:            sort,
+	}, nil
+}
+
+// maxPageSize caps page_size so one request can't pull the whole table.
+const maxPageSize = 100
+
+// parsePagination reads the page_size and page_number query params, defaulting
+// to the first page of ten. Both must be at least 1.
+func parsePagination(r *http.Request) (int, int, *api.ApiError) {
+	pageSizeParam := r.URL.Query().Get("page_size")
+
+	if pageSizeParam == "" {
+		pageSizeParam = "10"
+	}
+
+	pageSize, err := strconv.Atoi(pageSizeParam)
+	if err != nil || pageSize < 1 {
+		return 0, 0, &api.ApiError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("invalid page_size query param: %s", pageSizeParam),
+		}
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	pageNumberParam := r.URL.Query().Get("page_number")
+
+	if pageNumberParam == "" {
+		pageNumberParam = "1"
+	}
+
+	pageNumber, err := strconv.Atoi(pageNumberParam)
+	if err != nil || pageNumber < 1 {
+		return 0, 0, &api.ApiError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("invalid page_number query param: %s", pageNumberParam),
+		}
+	}
+
+	return pageSize, pageNumber, nil
+}
+
+// nextPageURL returns the request URL advanced by one page, or an empty string
+// when the current page was not full.
+func nextPageURL(r *http.Request, pageSize int, count int, pageNumber int) string {
+	if count < pageSize {
+		return ""
+	}
+
+	query := r.URL.Query()
+	query.Set("page_number", strconv.Itoa(pageNumber+1))
+
+	return fmt.Sprintf("%s?%s", r.URL.Path, query.Encode())
+}
+
+// parseCategoryFilter reads the optional category query param, which narrows
+// results to that category and its descendants.
+func parseCategoryFilter(query url.Values) pgtype.Text {
+	categoryID := query.Get("category")
+	if categoryID == "" {
+		return pgtype.Text{}
+	}
+
+	return pgtype.Text{String: categoryID, Valid: true}
+}
+
+// attributeFieldPrefix namespaces attribute inputs in listing forms and
+// search query params, e.g. attr_condition=like new.
+const attributeFieldPrefix = "attr_"
+
+// formAttributes collects the attribute values submitted alongside a form,
+// keyed by attribute name.
+func formAttributes(values url.Values) map[string]string {
+	attributes := map[string]string{}
+	for k := range values {
+		if name, ok := strings.CutPrefix(k, attributeFieldPrefix); ok {
+			attributes[name] = values.Get(k)
+		}
+	}
+
+	return attributes
+}
+
+// parseAttributeFilter encodes the non-empty attr_ query params as a JSONB
+// containment filter, or returns nil when there are none.
+func parseAttributeFilter(query url.Values) ([]byte, error) {
+	filter := map[string]string{}
+	for k, v := range formAttributes(query) {
+		if v = strings.TrimSpace(v); v != "" {
+			filter[k] = v
+		}
+	}
+
+	if len(filter) == 0 {
+		return nil, nil
+	}
+
+	return json.Marshal(filter)
+}
+
+// parseLocation resolves explicit coordinates, falling back to the centre of
+// a postal code, and rounds the result for privacy. Both values are invalid
+// when no location was given.
+func parseLocation(ctx context.Context, db PostalCodeLocator, latitude string, longitude string, postalCode string) (pgtype.Float8, pgtype.Float8, *api.ApiError) {
+	if latitude != "" && longitude != "" {
+		lat, latErr := strconv.ParseFloat(latitude, 64)
+		lng, lngErr := strconv.ParseFloat(longitude, 64)
+		if latErr != nil || lngErr != nil || !database.ValidCoordinates(lat, lng) {
+			return pgtype.Float8{}, pgtype.Float8{}, &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("invalid coordinates: %s, %s", latitude, longitude),
 			}
 		}
 
-		pageNumberParam := r.URL.Query().Get("page_number")
+		return pgtype.Float8{Float64: database.RoundCoordinate(lat), Valid: true},
+			pgtype.Float8{Float64: database.RoundCoordinate(lng), Valid: true},
+			nil
+	}
+
+	postalCode = database.NormalizePostalCode(postalCode)
+	if postalCode == "" {
+		return pgtype.Float8{}, pgtype.Float8{}, nil
+	}
 
-		if pageNumberParam == "" {
-			pageNumberParam = "1"
+	location, err := db.PostalCodeByCode(ctx, postalCode)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.Float8{}, pgtype.Float8{}, &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("unknown postal code: %s", postalCode),
+			}
 		}
+		return pgtype.Float8{}, pgtype.Float8{}, &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	return pgtype.Float8{Float64: database.RoundCoordinate(location.Latitude), Valid: true},
+		pgtype.Float8{Float64: database.RoundCoordinate(location.Longitude), Valid: true},
+		nil
+}
+
+// parseRadius reads the optional search radius in kilometres. A radius only
+// makes sense alongside a location.
+func parseRadius(value string, hasOrigin bool) (pgtype.Float8, *api.ApiError) {
+	if value == "" {
+		return pgtype.Float8{}, nil
+	}
+
+	radius, err := strconv.ParseFloat(value, 64)
+	if err != nil || radius <= 0 {
+		return pgtype.Float8{}, &api.ApiError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("invalid radius: %s", value),
+		}
+	}
+
+	if !hasOrigin {
+		return pgtype.Float8{}, &api.ApiError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("searching within a radius requires a location"),
+		}
+	}
+
+	return pgtype.Float8{Float64: radius, Valid: true}, nil
+}
+
+// parsePriceFilter converts an optional dollar amount into cents.
+func parsePriceFilter(value string) (pgtype.Int4, *api.ApiError) {
+	if value == "" {
+		return pgtype.Int4{}, nil
+	}
+
+	price, err := strconv.ParseFloat(value, 32)
+	if err != nil {
+		return pgtype.Int4{}, &api.ApiError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("invalid price format: %v", err),
+		}
+	}
+
+	return pgtype.Int4{Int32: int32(float32(price) * 100), Valid: true}, nil
+}
 
-		pageNumber, err := strconv.Atoi(pageNumberParam)
+// suggestSearch replaces each search term with the closest word from the
+// active listing vocabulary. It returns an empty string when no term changed.
+func suggestSearch(ctx context.Context, db ListingFetcher, input string) (string, error) {
+	terms := database.SearchTerms(input)
+	changed := false
+
+	for i, term := range terms {
+		word, err := db.SuggestSearchTerm(ctx, term)
 		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return "", err
+		}
+
+		if word != term {
+			terms[i] = word
+			changed = true
+		}
+	}
+
+	if !changed {
+		return "", nil
+	}
+
+	return strings.Join(terms, " "), nil
+}
+
+func HandlePopularListings(db TrendingListingsFetcher, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		pageSize, pageNumber, apiErr := parsePagination(r)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		query := r.URL.Query()
+
+		originLatitude, originLongitude, apiErr := parseLocation(r.Context(), db, query.Get("latitude"), query.Get("longitude"), query.Get("postal_code"))
+		if apiErr != nil {
+			return apiErr
+		}
+
+		sort := query.Get("sort")
+		if sort != "" && sort != database.ListingSortDistance {
 			return &api.ApiError{
-				Status: http.StatusInternalServerError,
-				Err:    err,
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("invalid sort query param: %s", sort),
+			}
+		}
+		if sort == database.ListingSortDistance && !originLatitude.Valid {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("sorting by distance requires a location"),
 			}
 		}
 
-		rows, err := db.ListingsByViews(r.Context(), database.ListingsByViewsParams{
-			Limit:  int32(pageSize),
-			Offset: int32((pageNumber - 1) * pageSize),
+		rows, err := db.TrendingListings(r.Context(), database.TrendingListingsParams{
+			CategoryID:      parseCategoryFilter(query),
+			Sort:            sort,
+			OriginLatitude:  originLatitude,
+			OriginLongitude: originLongitude,
+			PageSize:        int32(pageSize),
+			PageOffset:      int32((pageNumber - 1) * pageSize),
 		})
 
 		if err != nil {
@@ -138,8 +493,16 @@ func HandlePopularListings(db ListingsByViewsFetcher, authClient *auth.Client, s
 			claims = nil
 		}
 
+		watched, err := watchedListingIDs(r.Context(), db, claims, rows)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
 		w.WriteHeader(http.StatusOK)
-		templates.Listings("Popular Listings", rows, claims, token != "").Render(r.Context(), w)
+		templates.Listings("Trending Listings", rows, claims, watched, token != "").Render(r.Context(), w)
 
 		return nil
 	}
@@ -147,33 +510,32 @@ func HandlePopularListings(db ListingsByViewsFetcher, authClient *auth.Client, s
 
 func HandleMyListings(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
 	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
-		token := sm.GetString(r.Context(), "authToken")
-
-		if token == "" {
-			return &api.ApiError{
-				Status: http.StatusNotFound,
-				Err:    fmt.Errorf("failed to find session auth token"),
-			}
+		claims, apiErr := authClient.RequireClaims(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
 		}
 
-		claims, err := authClient.ParseJwtToken(token)
+		queries, tx, err := database.NewQueries(r.Context(), db)
 		if err != nil {
 			return &api.ApiError{
 				Status: http.StatusInternalServerError,
 				Err:    err,
 			}
 		}
+		defer tx.Rollback(r.Context())
 
-		queries, tx, err := database.NewQueries(r.Context(), db)
-		if err != nil {
+		status := r.URL.Query().Get("status")
+		if status != "" && !database.IsListingStatus(status) {
 			return &api.ApiError{
-				Status: http.StatusInternalServerError,
-				Err:    err,
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("invalid status query param: %s", status),
 			}
 		}
-		defer tx.Rollback(r.Context())
 
-		listings, err := queries.ListingsBySellerEmail(r.Context(), claims.Email)
+		listings, err := queries.ListingsBySellerEmail(r.Context(), database.ListingsBySellerEmailParams{
+			SellerEmail: claims.Email,
+			Status:      status,
+		})
 		if err != nil {
 			return &api.ApiError{
 				Status: http.StatusInternalServerError,
@@ -181,34 +543,38 @@ func HandleMyListings(db *pgxpool.Pool, authClient *auth.Client, sm *scs.Session
 			}
 		}
 
-		if len(listings) == 0 {
-			templates.NoResults().Render(r.Context(), w)
-			return nil
-		}
-
 		w.WriteHeader(http.StatusOK)
-		templates.Listings("My Listings", listings, nil, false).Render(r.Context(), w)
+		templates.MyListings(listings, status, claims).Render(r.Context(), w)
 
 		return nil
 	}
 }
 
-func HandlePostListings(db ListingRecorderFetcher, fsClient *seaweedfs.Client, config *api.Config) api.HandlerFuncWithError {
+func HandlePostListings(db *pgxpool.Pool, store storage.Store, nc *nats.Conn, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
 	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
-		// Parse multipart form with 10MB max memory
-		if err := r.ParseMultipartForm(10 << 20); err != nil {
-			return &api.ApiError{
-				Status: http.StatusBadRequest,
-				Err:    err,
-			}
+		claims, apiErr := authClient.RequireClaims(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		if apiErr := parseUploadForm(w, r); apiErr != nil {
+			return apiErr
 		}
 
+		// The form is checked, and its images stored, before the transaction
+		// opens, so the request doesn't hold a connection while uploading.
+		queries := database.New(db)
+
 		// Get form values
-		sellerEmail := r.FormValue("seller_email")
 		listingName := r.FormValue("listing_name")
 		description := r.FormValue("description")
 		priceStr := r.FormValue("price")
 
+		status := database.ListingStatusActive
+		if r.FormValue("status") == database.ListingStatusDraft {
+			status = database.ListingStatusDraft
+		}
+
 		// Convert price to float
 		price, err := strconv.ParseFloat(priceStr, 32)
 		if err != nil {
@@ -218,6 +584,58 @@ func HandlePostListings(db ListingRecorderFetcher, fsClient *seaweedfs.Client, c
 			}
 		}
 
+		categoryID := r.FormValue("category_id")
+		if categoryID == "" {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("category is required"),
+			}
+		}
+
+		if _, err := queries.CategoryByID(r.Context(), categoryID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusBadRequest,
+					Err:    fmt.Errorf("category not found: %s", categoryID),
+				}
+			}
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		postalCode := database.NormalizePostalCode(r.FormValue("postal_code"))
+
+		latitude, longitude, apiErr := parseLocation(r.Context(), queries, r.FormValue("latitude"), r.FormValue("longitude"), postalCode)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		schema, err := queries.CategoryAttributesByCategoryID(r.Context(), categoryID)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		attributes, err := database.ValidateListingAttributes(schema, formAttributes(r.Form))
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    err,
+			}
+		}
+
+		attributesJSON, err := json.Marshal(attributes)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
 		listingID, err := uuid.NewV4()
 		if err != nil {
 			return &api.ApiError{
@@ -225,56 +643,46 @@ func HandlePostListings(db ListingRecorderFetcher, fsClient *seaweedfs.Client, c
 				Err:    fmt.Errorf("unable to create listing id: %v", err),
 			}
 		}
-		imageURLs := []string{}
-		// Get image files
 		files := r.MultipartForm.File["images"]
-		if len(files) > 0 {
-			slog.Info("Processing images", "count", len(files))
-			// TODO: Save images to storage service, attach to listing
-			for i, fileHeader := range files {
-				slog.Info("Image file", "index", i, "filename", fileHeader.Filename, "size", fileHeader.Size)
-				f, err := fileHeader.Open()
-				if err != nil {
-					return &api.ApiError{
-						Status: http.StatusInternalServerError,
-						Err:    fmt.Errorf("unable to open image fileHeader: %v", err),
-					}
-				}
-				defer f.Close()
+		if apiErr := validateUploads(files, 0); apiErr != nil {
+			return apiErr
+		}
 
-				daResp, err := fsClient.DirAssign()
-				if err != nil {
-					return &api.ApiError{
-						Status: http.StatusInternalServerError,
-						Err:    fmt.Errorf("failed to assign a dir via fsClient: %v", err),
-					}
-				}
+		pending := database.New(db)
+		uploaded, apiErr := uploadImages(r.Context(), pending, store, files)
+		if apiErr != nil {
+			return apiErr
+		}
 
-				ufResp, err := fsClient.UploadFile(f, fileHeader.Filename, daResp.FID)
-				if err != nil {
-					return &api.ApiError{
-						Status: http.StatusInternalServerError,
-						Err:    fmt.Errorf("failed to upload file via fsClient: %v", err),
-					}
-				}
-				if ufResp.Size == 0 {
-					return &api.ApiError{
-						Status: http.StatusInternalServerError,
-						Err:    fmt.Errorf("failed to upload file via fsClient: %s", "image size is 0"),
-					}
-				}
+		committed := false
+		defer func() {
+			if !committed {
+				rollbackUploads(r.Context(), pending, store, uploaded.urls())
+			}
+		}()
 
-				imageURLs = append(imageURLs, fmt.Sprintf("%s/%s", config.SeaweedFS.VolumesURL, daResp.FID))
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
 			}
 		}
+		defer tx.Rollback(r.Context())
 
 		// Record the listing in the database
-		_, err = db.RecordListing(r.Context(), database.RecordListingParams{
+		_, err = queries.RecordListing(r.Context(), database.RecordListingParams{
 			ID:          listingID.String(),
-			SellerEmail: sellerEmail,
+			SellerEmail: claims.Email,
 			ListingName: listingName,
 			Description: description,
 			Price:       int32(float32(price) * 100),
+			Status:      status,
+			CategoryID:  categoryID,
+			Attributes:  attributesJSON,
+			PostalCode:  pgtype.Text{String: postalCode, Valid: postalCode != ""},
+			Latitude:    latitude,
+			Longitude:   longitude,
 		})
 		if err != nil {
 			return &api.ApiError{
@@ -283,10 +691,7 @@ func HandlePostListings(db ListingRecorderFetcher, fsClient *seaweedfs.Client, c
 			}
 		}
 
-		_, err = db.RecordListingImages(r.Context(), database.RecordListingImagesParams{
-			ListingID:     listingID.String(),
-			ImageUrlArray: imageURLs,
-		})
+		_, err = queries.RecordListingImages(r.Context(), uploaded.recordParams(listingID.String()))
 		if err != nil {
 			return &api.ApiError{
 				Status: http.StatusInternalServerError,
@@ -294,16 +699,45 @@ func HandlePostListings(db ListingRecorderFetcher, fsClient *seaweedfs.Client, c
 			}
 		}
 
-		listing, err := db.ListingByID(r.Context(), listingID.String())
+		if apiErr := checkListingSize(r.Context(), queries, listingID.String()); apiErr != nil {
+			return apiErr
+		}
+
+		if err := queries.CommitPendingUploads(r.Context(), uploaded.urls()); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		listing, err := queries.ListingByID(r.Context(), listingID.String())
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		if err := tx.Commit(r.Context()); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		committed = true
+
+		if listing.PublishedAt.Valid {
+			announceListing(nc, listing.ID)
+		}
 
 		w.WriteHeader(http.StatusOK)
-		templates.IndividualListing(listing, nil, false).Render(r.Context(), w)
+		templates.IndividualListing(listing, nil, false, false).Render(r.Context(), w)
 
 		return nil
 	}
 }
 
-func HandleDeleteListings(db *pgxpool.Pool) api.HandlerFuncWithError {
+func HandleDeleteListings(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
 	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
 		id := r.URL.Query().Get("id")
 
@@ -323,7 +757,42 @@ func HandleDeleteListings(db *pgxpool.Pool) api.HandlerFuncWithError {
 		}
 		defer tx.Rollback(r.Context())
 
-		listing, err := queries.DeleteListing(r.Context(), id)
+		_, existing, apiErr := authClient.RequireListingOwner(r.Context(), sm, queries, id)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		// Notify before deleting, as the watchlist rows go with the listing.
+		if apiErr := notifyWatchers(r.Context(), queries, existing, database.NotificationKindDeleted, existing.Price); apiErr != nil {
+			return apiErr
+		}
+
+		var listing database.Listing
+		if existing.Status == database.ListingStatusDraft {
+			// Drafts were never visible to buyers, so there is no history to keep.
+			// Their images are released for collection before the cascade
+			// removes the rows that reference them.
+			if err := queries.ReleaseListingImages(r.Context(), id); err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+			listing, err = queries.DeleteListing(r.Context(), id)
+		} else {
+			if !database.CanTransitionListing(existing.Status, database.ListingStatusArchived) {
+				return &api.ApiError{
+					Status: http.StatusConflict,
+					Err:    fmt.Errorf("listing is already %s", existing.Status),
+				}
+			}
+
+			listing, err = queries.UpdateListingStatus(r.Context(), database.UpdateListingStatusParams{
+				Status:     database.ListingStatusArchived,
+				ListingID:  id,
+				FromStatus: existing.Status,
+			})
+		}
 		if err != nil {
 			return &api.ApiError{
 				Status: http.StatusInternalServerError,
@@ -340,18 +809,127 @@ func HandleDeleteListings(db *pgxpool.Pool) api.HandlerFuncWithError {
 	}
 }
 
-func HandlePatchListing(db ListingViewsUpserter) api.HandlerFuncWithError {
+func HandlePostListingStatus(db *pgxpool.Pool, nc *nats.Conn, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
 	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
 		id := r.URL.Query().Get("id")
+		status := r.URL.Query().Get("status")
+		negotiationID := r.URL.Query().Get("negotiation_id")
 
-		if _, err := uuid.FromString(id); err != nil {
+		if !database.IsListingStatus(status) {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("invalid status query param: %s", status),
+			}
+		}
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		claims, listing, apiErr := authClient.RequireListingOwner(r.Context(), sm, queries, id)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		if !database.CanTransitionListing(listing.Status, status) {
+			return &api.ApiError{
+				Status: http.StatusConflict,
+				Err:    fmt.Errorf("listing cannot move from %s to %s", listing.Status, status),
+			}
+		}
+
+		params := database.UpdateListingStatusParams{
+			Status:     status,
+			ListingID:  listing.ID,
+			FromStatus: listing.Status,
+		}
+
+		// negotiation_id ties the change to a chat on this listing. One that
+		// belongs to another listing is ignored, so it can't be used to read
+		// someone else's offers.
+		var negotiation *database.NegotiationByIDRow
+		if negotiationID != "" {
+			n, err := queries.NegotiationByID(r.Context(), negotiationID)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+			if err == nil && n.ListingID == listing.ID {
+				negotiation = &n
+			}
+		}
+
+		if status == database.ListingStatusReserved || status == database.ListingStatusSold {
+			if negotiationID == "" {
+				return &api.ApiError{
+					Status: http.StatusBadRequest,
+					Err:    fmt.Errorf("failed to provide negotiation_id query param"),
+				}
+			}
+
+			if negotiation == nil {
+				return &api.ApiError{
+					Status: http.StatusBadRequest,
+					Err:    fmt.Errorf("negotiation %s does not belong to listing %s", negotiationID, listing.ID),
+				}
+			}
+
+			buyer := pgtype.Text{String: negotiation.ID, Valid: true}
+			if status == database.ListingStatusReserved {
+				params.ReservedNegotiationID = buyer
+			} else {
+				params.SoldNegotiationID = buyer
+
+				if database.CanTransitionNegotiation(negotiation.Status, database.NegotiationStatusCompleted) {
+					if apiErr := transitionNegotiation(r.Context(), queries, *negotiation, database.NegotiationStatusCompleted); apiErr != nil {
+						return apiErr
+					}
+				}
+			}
+		}
+
+		updated, err := queries.UpdateListingStatus(r.Context(), params)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusConflict,
+					Err:    fmt.Errorf("listing status changed concurrently: %s", listing.ID),
+				}
+			}
 			return &api.ApiError{
 				Status: http.StatusInternalServerError,
 				Err:    err,
 			}
 		}
 
-		_, err := db.UpsertListingViews(r.Context(), id)
+		if kind, ok := statusNotificationKinds[status]; ok {
+			if apiErr := notifyWatchers(r.Context(), queries, listing, kind, listing.Price); apiErr != nil {
+				return apiErr
+			}
+		}
+
+		// A draft going live is new to saved searches.
+		published := !listing.PublishedAt.Valid && updated.PublishedAt.Valid
+
+		// Actions taken from the chat window refresh the negotiation panel.
+		if negotiation != nil {
+			if apiErr := renderOffers(w, r, queries, tx, negotiation.ID, claims); apiErr != nil {
+				return apiErr
+			}
+			if published {
+				announceListing(nc, listing.ID)
+			}
+			return nil
+		}
+
+		listing, err = queries.ListingByID(r.Context(), listing.ID)
 		if err != nil {
 			return &api.ApiError{
 				Status: http.StatusInternalServerError,
@@ -359,6 +937,29 @@ func HandlePatchListing(db ListingViewsUpserter) api.HandlerFuncWithError {
 			}
 		}
 
+		if err := tx.Commit(r.Context()); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		if published {
+			announceListing(nc, listing.ID)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		templates.IndividualListing(listing, claims, false, true).Render(r.Context(), w)
+
 		return nil
 	}
 }
+
+// announceListing wakes the saved search matcher for a newly published
+// listing. Matching happens in the background, so a lost message only delays
+// alerts to the next sweep.
+func announceListing(nc *nats.Conn, listingID string) {
+	if err := nc.Publish(alerts.SubjectListingPublished, []byte(listingID)); err != nil {
+		slog.Error("failed to publish listing", "err", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -22,6 +23,7 @@ import (
 
 type MessageRecorder interface {
 	RecordMessage(context.Context, database.RecordMessageParams) (database.Message, error)
+	NegotiationByID(ctx context.Context, negotiationID string) (database.NegotiationByIDRow, error)
 }
 
 type PostMessageParams struct {
@@ -31,14 +33,20 @@ type PostMessageParams struct {
 
 func HandleMessageWS(db MessageRecorder, authClient *auth.Client, nc *nats.Conn, sm *scs.SessionManager) api.HandlerFuncWithError {
 	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
-		claims, err := authClient.GetClaims(r.Context(), sm)
-		if err != nil {
+		negotiationID := r.URL.Query().Get("negotiation_id")
+		if negotiationID == "" {
 			return &api.ApiError{
-				Status: http.StatusUnauthorized,
-				Err:    err,
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("failed to provide negotiation_id query param"),
 			}
 		}
 
+		// Authorize before upgrading so the rejection is still a plain HTTP response.
+		claims, _, apiErr := authClient.RequireNegotiationMember(r.Context(), sm, db, negotiationID)
+		if apiErr != nil {
+			return apiErr
+		}
+
 		c, err := websocket.Accept(w, r, nil)
 		if err != nil {
 			return &api.ApiError{
@@ -53,18 +61,6 @@ func HandleMessageWS(db MessageRecorder, authClient *auth.Client, nc *nats.Conn,
 		ctx, cancel := context.WithTimeout(context.Background(), time.Minute*30)
 		defer cancel()
 
-		// Get initial negotiation ID
-		var initialParams PostMessageParams
-		err = wsjson.Read(ctx, c, &initialParams)
-		if err != nil {
-			return &api.ApiError{
-				Status: http.StatusInternalServerError,
-				Err:    err,
-			}
-		}
-
-		negotiationID := initialParams.NegotiationID
-
 		nc.Subscribe(negotiationID, func(msg *nats.Msg) {
 			var m database.Message
 			var msgErr error
@@ -86,7 +82,7 @@ func HandleMessageWS(db MessageRecorder, authClient *auth.Client, nc *nats.Conn,
 			c.Write(ctx, websocket.MessageText, d)
 		})
 
-		slog.Info("Subscribed", "topic", initialParams.NegotiationID, "user", claims.Email)
+		slog.Info("Subscribed", "topic", negotiationID, "user", claims.Email)
 
 		for {
 			var params PostMessageParams
@@ -103,6 +99,17 @@ func HandleMessageWS(db MessageRecorder, authClient *auth.Client, nc *nats.Conn,
 
 			slog.Info("recieved message from ws", "msg", params)
 
+			negotiation, err := db.NegotiationByID(ctx, negotiationID)
+			if err != nil {
+				slog.Error("failed to fetch negotiation", "err", err)
+				continue
+			}
+
+			if !database.NegotiationAcceptsMessages(negotiation.Status) {
+				slog.Warn("negotiation no longer accepts messages, skipping...", "negotiation", negotiationID, "status", negotiation.Status)
+				continue
+			}
+
 			p := database.RecordMessageParams{
 				NegotiationID: negotiationID,
 				SenderEmail:   claims.Email,
@@ -150,22 +157,26 @@ func HandlePostMessage(db *pgxpool.Pool, sm *scs.SessionManager, authClient *aut
 			}
 		}
 
-		claims, err := authClient.GetClaims(r.Context(), sm)
+		queries, tx, err := database.NewQueries(r.Context(), db)
 		if err != nil {
 			return &api.ApiError{
-				Status: http.StatusUnauthorized,
+				Status: http.StatusInternalServerError,
 				Err:    err,
 			}
 		}
+		defer tx.Rollback(r.Context())
+
+		claims, negotiation, apiErr := authClient.RequireNegotiationMember(r.Context(), sm, queries, params.NegotiationID)
+		if apiErr != nil {
+			return apiErr
+		}
 
-		queries, tx, err := database.NewQueries(r.Context(), db)
-		if err != nil {
+		if !database.NegotiationAcceptsMessages(negotiation.Status) {
 			return &api.ApiError{
-				Status: http.StatusInternalServerError,
-				Err:    err,
+				Status: http.StatusConflict,
+				Err:    fmt.Errorf("negotiation is %s and no longer accepts messages", negotiation.Status),
 			}
 		}
-		defer tx.Rollback(r.Context())
 
 		_, err = queries.RecordMessage(r.Context(), database.RecordMessageParams{
 			NegotiationID: params.NegotiationID,
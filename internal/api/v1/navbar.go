@@ -10,7 +10,7 @@ import (
 	"github.com/alexedwards/scs/v2"
 )
 
-func HandleNavbar(sm *scs.SessionManager, authClient *auth.Client) api.HandlerFuncWithError {
+func HandleNavbar(db UnreadNotificationCounter, sm *scs.SessionManager, authClient *auth.Client) api.HandlerFuncWithError {
 	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
 		claims, _ := authClient.GetClaims(r.Context(), sm)
 
@@ -34,9 +34,22 @@ func HandleNavbar(sm *scs.SessionManager, authClient *auth.Client) api.HandlerFu
 				Name:  "search",
 				Icon:  "search",
 			},
+			{
+				Route: "/categories",
+				Name:  "categories",
+				Icon:  "grid",
+			},
 		}
 
 		if claims != nil {
+			unread, err := db.UnreadNotificationCount(r.Context(), claims.Email)
+			if err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+
 			items = append(
 				items,
 				templates.NavbarItemData{
@@ -49,10 +62,21 @@ func HandleNavbar(sm *scs.SessionManager, authClient *auth.Client) api.HandlerFu
 					Name:  "createlisting",
 					Icon:  "plus-square",
 				},
+				templates.NavbarItemData{
+					Route: "/watchlist",
+					Name:  "saved",
+					Icon:  "heart",
+					Badge: int(unread),
+				},
 				templates.NavbarItemData{
 					Route: "/negotiations",
 					Name:  "negotiations",
 					Icon:  "dollar-sign",
+				},
+				templates.NavbarItemData{
+					Route: "/analytics",
+					Name:  "analytics",
+					Icon:  "bar-chart-2",
 				})
 		}
 
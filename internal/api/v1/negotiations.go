@@ -2,6 +2,7 @@ package v1
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -10,31 +11,26 @@ import (
 	"github.com/DillonEnge/jolt/internal/auth"
 	"github.com/DillonEnge/jolt/templates"
 	"github.com/alexedwards/scs/v2"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type NegotiationQuerier interface {
 	RecordNegotiation(ctx context.Context, arg database.RecordNegotiationParams) (database.Negotiation, error)
 	NegotiationByListingIDAndBuyerEmail(ctx context.Context, arg database.NegotiationByListingIDAndBuyerEmailParams) (database.Negotiation, error)
+	UpdateNegotiationStatus(ctx context.Context, arg database.UpdateNegotiationStatusParams) (database.Negotiation, error)
+	ListingByID(ctx context.Context, listingID string) (database.ListingWithImageUrl, error)
+}
+
+type NegotiationStatusUpdater interface {
+	UpdateNegotiationStatus(ctx context.Context, arg database.UpdateNegotiationStatusParams) (database.Negotiation, error)
 }
 
 func HandleNegotiations(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
 	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
-		token := sm.GetString(r.Context(), "authToken")
-
-		if token == "" {
-			return &api.ApiError{
-				Status: http.StatusNotFound,
-				Err:    fmt.Errorf("failed to find session auth token"),
-			}
-		}
-
-		claims, err := authClient.ParseJwtToken(token)
-		if err != nil {
-			return &api.ApiError{
-				Status: http.StatusInternalServerError,
-				Err:    err,
-			}
+		claims, apiErr := authClient.RequireClaims(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
 		}
 
 		tx, err := db.Begin(r.Context())
@@ -65,14 +61,39 @@ func HandlePostNegotiation(db NegotiationQuerier, authClient *auth.Client, sm *s
 			}
 		}
 
-		claims, err := authClient.GetClaims(r.Context(), sm)
+		claims, apiErr := authClient.RequireClaims(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		listing, err := db.ListingByID(r.Context(), listingID)
 		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusNotFound,
+					Err:    fmt.Errorf("listing not found: %s", listingID),
+				}
+			}
 			return &api.ApiError{
 				Status: http.StatusInternalServerError,
 				Err:    err,
 			}
 		}
 
+		if listing.Status != database.ListingStatusActive {
+			return &api.ApiError{
+				Status: http.StatusConflict,
+				Err:    fmt.Errorf("listing is %s and not accepting bids", listing.Status),
+			}
+		}
+
+		if listing.SellerEmail == claims.Email {
+			return &api.ApiError{
+				Status: http.StatusForbidden,
+				Err:    fmt.Errorf("sellers cannot bid on their own listing"),
+			}
+		}
+
 		negotiation, err := db.RecordNegotiation(r.Context(), database.RecordNegotiationParams{
 			ListingID:  listingID,
 			BuyerEmail: claims.Email,
@@ -85,8 +106,105 @@ func HandlePostNegotiation(db NegotiationQuerier, authClient *auth.Client, sm *s
 			})
 		}
 
+		if negotiation.Status == database.NegotiationStatusCancelled {
+			negotiation, err = db.UpdateNegotiationStatus(r.Context(), database.UpdateNegotiationStatusParams{
+				Status:        database.NegotiationStatusOpen,
+				NegotiationID: negotiation.ID,
+				FromStatus:    database.NegotiationStatusCancelled,
+			})
+			if err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+		}
+
 		templates.Loader(fmt.Sprintf("/chat?negotiation_id=%s", negotiation.ID)).Render(r.Context(), w)
 
 		return nil
 	}
 }
+
+// HandleCompleteNegotiation marks an accepted negotiation as completed once
+// the item has changed hands.
+func HandleCompleteNegotiation(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return handleNegotiationTransition(db, authClient, sm, database.NegotiationStatusCompleted)
+}
+
+// HandleCancelNegotiation lets either participant walk away from a negotiation.
+func HandleCancelNegotiation(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return handleNegotiationTransition(db, authClient, sm, database.NegotiationStatusCancelled)
+}
+
+func handleNegotiationTransition(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager, to string) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		negotiationID := r.URL.Query().Get("negotiation_id")
+		if negotiationID == "" {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("failed to provide negotiation_id query param"),
+			}
+		}
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		claims, negotiation, apiErr := authClient.RequireNegotiationMember(r.Context(), sm, queries, negotiationID)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		if apiErr := transitionNegotiation(r.Context(), queries, negotiation, to); apiErr != nil {
+			return apiErr
+		}
+
+		if to == database.NegotiationStatusCancelled {
+			if err := queries.SupersedePendingOffers(r.Context(), negotiation.ID); err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+		}
+
+		return renderOffers(w, r, queries, tx, negotiation.ID, claims)
+	}
+}
+
+// transitionNegotiation moves a negotiation to the given status, rejecting
+// transitions the current status does not allow.
+func transitionNegotiation(ctx context.Context, db NegotiationStatusUpdater, negotiation database.NegotiationByIDRow, to string) *api.ApiError {
+	if !database.CanTransitionNegotiation(negotiation.Status, to) {
+		return &api.ApiError{
+			Status: http.StatusConflict,
+			Err:    fmt.Errorf("negotiation cannot move from %s to %s", negotiation.Status, to),
+		}
+	}
+
+	_, err := db.UpdateNegotiationStatus(ctx, database.UpdateNegotiationStatusParams{
+		Status:        to,
+		NegotiationID: negotiation.ID,
+		FromStatus:    negotiation.Status,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &api.ApiError{
+				Status: http.StatusConflict,
+				Err:    fmt.Errorf("negotiation status changed concurrently: %s", negotiation.ID),
+			}
+		}
+		return &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	return nil
+}
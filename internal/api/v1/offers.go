@@ -0,0 +1,267 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/DillonEnge/jolt/internal/auth"
+	"github.com/DillonEnge/jolt/templates"
+	"github.com/alexedwards/scs/v2"
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// HandlePostOffer records a priced offer from the buyer on a negotiation.
+func HandlePostOffer(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return handleSubmitOffer(db, authClient, sm, database.OfferKindOffer)
+}
+
+// HandlePostCounterOffer records a counter-offer from the seller on a negotiation.
+func HandlePostCounterOffer(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return handleSubmitOffer(db, authClient, sm, database.OfferKindCounter)
+}
+
+// HandleAcceptOffer accepts the pending offer made by the other party.
+func HandleAcceptOffer(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return handleRespondToOffer(db, authClient, sm, database.OfferStatusAccepted)
+}
+
+// HandleRejectOffer rejects the pending offer made by the other party.
+func HandleRejectOffer(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return handleRespondToOffer(db, authClient, sm, database.OfferStatusRejected)
+}
+
+func handleSubmitOffer(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager, kind string) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		negotiationID := r.URL.Query().Get("negotiation_id")
+		if negotiationID == "" {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("failed to provide negotiation_id query param"),
+			}
+		}
+
+		amount, err := strconv.ParseFloat(r.FormValue("amount"), 32)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("invalid amount format: %v", err),
+			}
+		}
+		if amount <= 0 {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("amount must be greater than zero"),
+			}
+		}
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		claims, negotiation, apiErr := authClient.RequireNegotiationMember(r.Context(), sm, queries, negotiationID)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		sender := negotiation.BuyerEmail
+		if kind == database.OfferKindCounter {
+			sender = negotiation.SellerEmail
+		}
+		if claims.Email != sender {
+			return &api.ApiError{
+				Status: http.StatusForbidden,
+				Err:    fmt.Errorf("only the %s may send an %s on this negotiation", roleForKind(kind), kind),
+			}
+		}
+
+		if apiErr := transitionNegotiation(r.Context(), queries, negotiation, database.NegotiationStatusOfferPending); apiErr != nil {
+			return apiErr
+		}
+
+		if err := queries.SupersedePendingOffers(r.Context(), negotiation.ID); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		offer, err := queries.RecordOffer(r.Context(), database.RecordOfferParams{
+			NegotiationID: negotiation.ID,
+			SenderEmail:   claims.Email,
+			Kind:          kind,
+			Amount:        int32(float32(amount) * 100),
+		})
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		if kind == database.OfferKindOffer {
+			_, err = queries.UpdateNegotiationBid(r.Context(), database.UpdateNegotiationBidParams{
+				Bid:           offer.Amount,
+				NegotiationID: negotiation.ID,
+			})
+		} else {
+			_, err = queries.UpdateNegotiationAsk(r.Context(), database.UpdateNegotiationAskParams{
+				Ask:           offer.Amount,
+				NegotiationID: negotiation.ID,
+			})
+		}
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		return renderOffers(w, r, queries, tx, negotiation.ID, claims)
+	}
+}
+
+func handleRespondToOffer(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager, status string) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		offerID := r.URL.Query().Get("offer_id")
+		if offerID == "" {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("failed to provide offer_id query param"),
+			}
+		}
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		offer, err := queries.OfferByID(r.Context(), offerID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusNotFound,
+					Err:    fmt.Errorf("offer not found: %s", offerID),
+				}
+			}
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		claims, negotiation, apiErr := authClient.RequireNegotiationMember(r.Context(), sm, queries, offer.NegotiationID)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		if claims.Email == offer.SenderEmail {
+			return &api.ApiError{
+				Status: http.StatusForbidden,
+				Err:    fmt.Errorf("only the receiving party may respond to an offer"),
+			}
+		}
+
+		nextStatus := database.NegotiationStatusOpen
+		if status == database.OfferStatusAccepted {
+			nextStatus = database.NegotiationStatusAccepted
+		}
+		if apiErr := transitionNegotiation(r.Context(), queries, negotiation, nextStatus); apiErr != nil {
+			return apiErr
+		}
+
+		offer, err = queries.UpdateOfferStatus(r.Context(), database.UpdateOfferStatusParams{
+			Status:  status,
+			OfferID: offer.ID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusConflict,
+					Err:    fmt.Errorf("offer is no longer pending: %s", offerID),
+				}
+			}
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		if status == database.OfferStatusAccepted {
+			// An accepted offer settles the price for both sides.
+			if _, err := queries.UpdateNegotiationBid(r.Context(), database.UpdateNegotiationBidParams{
+				Bid:           offer.Amount,
+				NegotiationID: negotiation.ID,
+			}); err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+			if _, err := queries.UpdateNegotiationAsk(r.Context(), database.UpdateNegotiationAskParams{
+				Ask:           offer.Amount,
+				NegotiationID: negotiation.ID,
+			}); err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+		}
+
+		return renderOffers(w, r, queries, tx, negotiation.ID, claims)
+	}
+}
+
+// renderOffers commits tx and renders the refreshed offer history for the
+// negotiation so HTMX can swap it into the chat window.
+func renderOffers(w http.ResponseWriter, r *http.Request, queries *database.Queries, tx pgx.Tx, negotiationID string, claims *casdoorsdk.Claims) *api.ApiError {
+	offers, err := queries.OffersByNegotiationID(r.Context(), negotiationID)
+	if err != nil {
+		return &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	negotiation, err := queries.NegotiationByID(r.Context(), negotiationID)
+	if err != nil {
+		return &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		return &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	templates.Offers(offers, negotiation, claims).Render(r.Context(), w)
+
+	return nil
+}
+
+func roleForKind(kind string) string {
+	if kind == database.OfferKindCounter {
+		return "seller"
+	}
+
+	return "buyer"
+}
@@ -0,0 +1,74 @@
+package v1
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/DillonEnge/jolt/internal/auth"
+	"github.com/DillonEnge/jolt/templates"
+	"github.com/a-h/templ"
+	"github.com/alexedwards/scs/v2"
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+// HandlePage serves h as-is to HTMX, and wraps its fragment in the full
+// layout for direct navigation, refreshes and history restores. active names
+// the navbar item to highlight.
+func HandlePage(h api.HandlerFuncWithError, active string, sm *scs.SessionManager, authClient *auth.Client, config *api.Config) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		w.Header().Add("Vary", "HX-Request")
+
+		if isFragmentRequest(r) {
+			return h(w, r)
+		}
+
+		fragment := &fragmentWriter{header: w.Header(), status: http.StatusOK}
+		if apiErr := h(fragment, r); apiErr != nil {
+			return apiErr
+		}
+
+		var claims *casdoorsdk.Claims
+		if token := sm.GetString(r.Context(), "authToken"); token != "" {
+			var err error
+			claims, err = authClient.ParseJwtToken(token)
+			if err != nil {
+				slog.Error("failed to decode token", "err", err)
+				claims = nil
+			}
+		}
+
+		w.WriteHeader(fragment.status)
+		templates.Layout(claims, config, nil, templ.Raw(fragment.body.String()), active).Render(r.Context(), w)
+
+		return nil
+	}
+}
+
+// isFragmentRequest reports whether HTMX will swap the response into an
+// existing page. History restores replace the whole body, so they need the
+// full layout even though HTMX makes them.
+func isFragmentRequest(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true" && r.Header.Get("HX-History-Restore-Request") != "true"
+}
+
+// fragmentWriter buffers a fragment handler's response so it can be wrapped
+// before anything is sent.
+type fragmentWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (f *fragmentWriter) Header() http.Header {
+	return f.header
+}
+
+func (f *fragmentWriter) Write(b []byte) (int, error) {
+	return f.body.Write(b)
+}
+
+func (f *fragmentWriter) WriteHeader(status int) {
+	f.status = status
+}
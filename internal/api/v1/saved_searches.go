@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/DillonEnge/jolt/internal/auth"
+	"github.com/DillonEnge/jolt/templates"
+	"github.com/alexedwards/scs/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+type SavedSearchQuerier interface {
+	PostalCodeLocator
+	RecordSavedSearch(ctx context.Context, arg database.RecordSavedSearchParams) (database.SavedSearch, error)
+	UpdateSavedSearchFrequency(ctx context.Context, arg database.UpdateSavedSearchFrequencyParams) (database.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, arg database.DeleteSavedSearchParams) (int64, error)
+}
+
+// savedSearchControls are form fields that configure the saved search itself
+// rather than the search it runs.
+var savedSearchControls = []string{"search_name", "frequency", "title", "page_size", "page_number"}
+
+// HandlePostSavedSearch saves the filters submitted from the search page so
+// the matcher can alert the user to new listings that match them.
+func HandlePostSavedSearch(db SavedSearchQuerier, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		claims, apiErr := authClient.RequireClaims(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		if err := r.ParseForm(); err != nil {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    err,
+			}
+		}
+
+		frequency := r.Form.Get("frequency")
+		if !database.IsAlertFrequency(frequency) {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("invalid alert frequency: %s", frequency),
+			}
+		}
+
+		query := savedSearchQuery(r.Form)
+
+		name := strings.TrimSpace(r.Form.Get("search_name"))
+		if name == "" {
+			name = strings.TrimSpace(query.Get("name"))
+		}
+		if name == "" {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("saved search name cannot be empty"),
+			}
+		}
+
+		params, apiErr := parseSearchParams(r.Context(), db, query)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		paramsJSON, err := json.Marshal(params)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		search, err := db.RecordSavedSearch(r.Context(), database.RecordSavedSearchParams{
+			UserEmail: claims.Email,
+			Name:      name,
+			Query:     query.Encode(),
+			Params:    paramsJSON,
+			Frequency: frequency,
+		})
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		templates.SavedSearchConfirmation(search).Render(r.Context(), w)
+
+		return nil
+	}
+}
+
+func HandlePutSavedSearch(db SavedSearchQuerier, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("failed to provide id query param"),
+			}
+		}
+
+		frequency := r.FormValue("frequency")
+		if !database.IsAlertFrequency(frequency) {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("invalid alert frequency: %s", frequency),
+			}
+		}
+
+		claims, apiErr := authClient.RequireClaims(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		search, err := db.UpdateSavedSearchFrequency(r.Context(), database.UpdateSavedSearchFrequencyParams{
+			Frequency:     frequency,
+			SavedSearchID: id,
+			UserEmail:     claims.Email,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusNotFound,
+					Err:    fmt.Errorf("saved search not found: %s", id),
+				}
+			}
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		templates.SavedSearch(search).Render(r.Context(), w)
+
+		return nil
+	}
+}
+
+func HandleDeleteSavedSearch(db SavedSearchQuerier, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("failed to provide id query param"),
+			}
+		}
+
+		claims, apiErr := authClient.RequireClaims(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		deleted, err := db.DeleteSavedSearch(r.Context(), database.DeleteSavedSearchParams{
+			SavedSearchID: id,
+			UserEmail:     claims.Email,
+		})
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		if deleted == 0 {
+			return &api.ApiError{
+				Status: http.StatusNotFound,
+				Err:    fmt.Errorf("saved search not found: %s", id),
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		return nil
+	}
+}
+
+// savedSearchQuery strips the saved search controls and empty filters from
+// the submitted form, leaving the query that re-runs the search.
+func savedSearchQuery(form url.Values) url.Values {
+	query := url.Values{}
+	for k, v := range form {
+		if len(v) > 0 && strings.TrimSpace(v[0]) != "" {
+			query.Set(k, v[0])
+		}
+	}
+
+	for _, k := range savedSearchControls {
+		query.Del(k)
+	}
+
+	return query
+}
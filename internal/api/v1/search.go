@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/DillonEnge/jolt/templates"
+	"github.com/alexedwards/scs/v2"
+)
+
+func HandleSearch(db CategoryLister, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		categories, err := db.CategoriesWithListingCounts(r.Context())
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		authed := sm.GetString(r.Context(), "authToken") != ""
+
+		templates.Search(categories, authed).Render(r.Context(), w)
+
+		return nil
+	}
+}
@@ -0,0 +1,166 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/DillonEnge/jolt/internal/auth"
+	"github.com/alexedwards/scs/v2"
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// viewDedupeWindow is how long repeat views of a listing by the same viewer
+// are ignored.
+const viewDedupeWindow = 30 * time.Minute
+
+// crawlerUserAgents are user agent fragments of clients that never count as
+// views.
+var crawlerUserAgents = []string{
+	"bot",
+	"crawler",
+	"spider",
+	"slurp",
+	"headless",
+	"curl",
+	"wget",
+	"python-requests",
+	"go-http-client",
+}
+
+// HandlePatchListing records a view of a listing. Views from the listing's
+// seller, from crawlers, and repeat views by the same viewer inside the
+// dedupe window are dropped before they reach the counters.
+func HandlePatchListing(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		id := r.URL.Query().Get("id")
+
+		if _, err := uuid.FromString(id); err != nil {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("invalid listing id: %s", id),
+			}
+		}
+
+		// Views are only reported by htmx as cards scroll into view.
+		if r.Header.Get("HX-Request") != "true" || isCrawler(r.UserAgent()) {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		listing, err := queries.ListingByID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusNotFound,
+					Err:    fmt.Errorf("listing not found: %s", id),
+				}
+			}
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		claims, _ := authClient.GetClaims(r.Context(), sm)
+		if claims != nil && strings.EqualFold(claims.Email, listing.SellerEmail) {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+
+		key := viewerKey(r.Context(), sm, claims)
+
+		// The dedupe check has to run after the lock, in its own statement, to
+		// see a view committed by a request that held the lock before us.
+		err = queries.LockListingViewer(r.Context(), database.LockListingViewerParams{
+			ListingID: listing.ID,
+			ViewerKey: key,
+		})
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		recorded, err := queries.RecordListingViewEvent(r.Context(), database.RecordListingViewEventParams{
+			ListingID:     listing.ID,
+			ViewerKey:     key,
+			DedupeSeconds: int32(viewDedupeWindow.Seconds()),
+		})
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		if recorded > 0 {
+			if _, err := queries.UpsertListingViews(r.Context(), listing.ID); err != nil {
+				return &api.ApiError{
+					Status: http.StatusInternalServerError,
+					Err:    err,
+				}
+			}
+		}
+
+		if err := tx.Commit(r.Context()); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+		return nil
+	}
+}
+
+// viewerKey identifies a viewer for deduplication: signed in users by email,
+// everyone else by a random id kept in their session.
+func viewerKey(ctx context.Context, sm *scs.SessionManager, claims *casdoorsdk.Claims) string {
+	if claims != nil {
+		return "user:" + strings.ToLower(claims.Email)
+	}
+
+	viewerID := sm.GetString(ctx, "viewerID")
+	if viewerID == "" {
+		viewerID = uuid.Must(uuid.NewV4()).String()
+		sm.Put(ctx, "viewerID", viewerID)
+	}
+
+	return "session:" + viewerID
+}
+
+func isCrawler(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	if userAgent == "" {
+		return true
+	}
+
+	for _, v := range crawlerUserAgents {
+		if strings.Contains(userAgent, v) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,223 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/DillonEnge/jolt/internal/auth"
+	"github.com/DillonEnge/jolt/templates"
+	"github.com/alexedwards/scs/v2"
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WatchlistQuerier interface {
+	ListingByID(ctx context.Context, listingID string) (database.ListingWithImageUrl, error)
+	AddToWatchlist(ctx context.Context, arg database.AddToWatchlistParams) error
+	RemoveFromWatchlist(ctx context.Context, arg database.RemoveFromWatchlistParams) error
+}
+
+type WatchedListingIDsFetcher interface {
+	WatchedListingIDs(ctx context.Context, arg database.WatchedListingIDsParams) ([]string, error)
+}
+
+type UnreadNotificationCounter interface {
+	UnreadNotificationCount(ctx context.Context, userEmail string) (int32, error)
+}
+
+// HandleWatchlist renders the signed-in user's saved listings and searches
+// alongside their recent notifications, marking those notifications as read.
+func HandleWatchlist(db *pgxpool.Pool, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		claims, apiErr := authClient.RequireClaims(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		queries, tx, err := database.NewQueries(r.Context(), db)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+		defer tx.Rollback(r.Context())
+
+		listings, err := queries.WatchedListings(r.Context(), claims.Email)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		notifications, err := queries.NotificationsByEmail(r.Context(), claims.Email)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		searches, err := queries.SavedSearchesByEmail(r.Context(), claims.Email)
+		if err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		if err := queries.MarkNotificationsRead(r.Context(), claims.Email); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		if err := tx.Commit(r.Context()); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		templates.Watchlist(listings, notifications, searches, claims).Render(r.Context(), w)
+
+		return nil
+	}
+}
+
+func HandlePostWatchlist(db WatchlistQuerier, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		listingID := r.URL.Query().Get("listing_id")
+		if listingID == "" {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("failed to provide listing_id query param"),
+			}
+		}
+
+		claims, apiErr := authClient.RequireClaims(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		listing, err := db.ListingByID(r.Context(), listingID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &api.ApiError{
+					Status: http.StatusNotFound,
+					Err:    fmt.Errorf("listing not found: %s", listingID),
+				}
+			}
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		if listing.SellerEmail == claims.Email {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("sellers cannot save their own listing"),
+			}
+		}
+
+		if err := db.AddToWatchlist(r.Context(), database.AddToWatchlistParams{
+			UserEmail: claims.Email,
+			ListingID: listing.ID,
+		}); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		templates.WatchButton(listing.ID, true).Render(r.Context(), w)
+
+		return nil
+	}
+}
+
+func HandleDeleteWatchlist(db WatchlistQuerier, authClient *auth.Client, sm *scs.SessionManager) api.HandlerFuncWithError {
+	return func(w http.ResponseWriter, r *http.Request) *api.ApiError {
+		listingID := r.URL.Query().Get("listing_id")
+		if listingID == "" {
+			return &api.ApiError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("failed to provide listing_id query param"),
+			}
+		}
+
+		claims, apiErr := authClient.RequireClaims(r.Context(), sm)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		if err := db.RemoveFromWatchlist(r.Context(), database.RemoveFromWatchlistParams{
+			UserEmail: claims.Email,
+			ListingID: listingID,
+		}); err != nil {
+			return &api.ApiError{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			}
+		}
+
+		templates.WatchButton(listingID, false).Render(r.Context(), w)
+
+		return nil
+	}
+}
+
+// notifyWatchers records a notification of the given kind for everyone
+// watching listing. newPrice is only used for price drops.
+func notifyWatchers(ctx context.Context, queries *database.Queries, listing database.ListingWithImageUrl, kind string, newPrice int32) *api.ApiError {
+	if err := queries.NotifyWatchers(ctx, database.NotifyWatchersParams{
+		Kind:      kind,
+		Message:   database.NotificationMessage(kind, listing, newPrice),
+		ListingID: listing.ID,
+	}); err != nil {
+		return &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	return nil
+}
+
+// watchedListingIDs reports which of listings the viewer has saved, so a page
+// of cards renders its save buttons from a single query. Signed-out viewers
+// get a nil set.
+func watchedListingIDs(ctx context.Context, db WatchedListingIDsFetcher, claims *casdoorsdk.Claims, listings []database.ListingWithImageUrl) (map[string]bool, error) {
+	if claims == nil || len(listings) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(listings))
+	for i, l := range listings {
+		ids[i] = l.ID
+	}
+
+	watchedIDs, err := db.WatchedListingIDs(ctx, database.WatchedListingIDsParams{
+		UserEmail:  claims.Email,
+		ListingIds: ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	watched := make(map[string]bool, len(watchedIDs))
+	for _, id := range watchedIDs {
+		watched[id] = true
+	}
+
+	return watched, nil
+}
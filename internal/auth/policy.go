@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/alexedwards/scs/v2"
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/jackc/pgx/v5"
+)
+
+type ListingFetcher interface {
+	ListingByID(ctx context.Context, listingID string) (database.ListingWithImageUrl, error)
+}
+
+type NegotiationFetcher interface {
+	NegotiationByID(ctx context.Context, negotiationID string) (database.NegotiationByIDRow, error)
+}
+
+// RequireClaims returns the signed-in user's claims, or a 401 if the session
+// carries no valid token.
+func (c *Client) RequireClaims(ctx context.Context, sm *scs.SessionManager) (*casdoorsdk.Claims, *api.ApiError) {
+	claims, err := c.GetClaims(ctx, sm)
+	if err != nil {
+		return nil, &api.ApiError{
+			Status: http.StatusUnauthorized,
+			Err:    err,
+		}
+	}
+
+	return claims, nil
+}
+
+// RequireAdmin returns the signed-in user's claims if they are a casdoor
+// admin, or a 403 otherwise.
+func (c *Client) RequireAdmin(ctx context.Context, sm *scs.SessionManager) (*casdoorsdk.Claims, *api.ApiError) {
+	claims, apiErr := c.RequireClaims(ctx, sm)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if !claims.IsAdmin {
+		return nil, &api.ApiError{
+			Status: http.StatusForbidden,
+			Err:    fmt.Errorf("only admins may perform this action"),
+		}
+	}
+
+	return claims, nil
+}
+
+// RequireListingOwner authorizes the signed-in user as the seller of the
+// given listing.
+func (c *Client) RequireListingOwner(ctx context.Context, sm *scs.SessionManager, db ListingFetcher, listingID string) (*casdoorsdk.Claims, database.ListingWithImageUrl, *api.ApiError) {
+	claims, apiErr := c.RequireClaims(ctx, sm)
+	if apiErr != nil {
+		return nil, database.ListingWithImageUrl{}, apiErr
+	}
+
+	listing, err := db.ListingByID(ctx, listingID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, listing, &api.ApiError{
+				Status: http.StatusNotFound,
+				Err:    fmt.Errorf("listing not found: %s", listingID),
+			}
+		}
+		return nil, listing, &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	if listing.SellerEmail != claims.Email {
+		return nil, listing, &api.ApiError{
+			Status: http.StatusForbidden,
+			Err:    fmt.Errorf("only the seller may modify listing %s", listingID),
+		}
+	}
+
+	return claims, listing, nil
+}
+
+// RequireNegotiationMember authorizes the signed-in user as either the buyer
+// or the seller on the given negotiation.
+func (c *Client) RequireNegotiationMember(ctx context.Context, sm *scs.SessionManager, db NegotiationFetcher, negotiationID string) (*casdoorsdk.Claims, database.NegotiationByIDRow, *api.ApiError) {
+	claims, apiErr := c.RequireClaims(ctx, sm)
+	if apiErr != nil {
+		return nil, database.NegotiationByIDRow{}, apiErr
+	}
+
+	negotiation, err := db.NegotiationByID(ctx, negotiationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, negotiation, &api.ApiError{
+				Status: http.StatusNotFound,
+				Err:    fmt.Errorf("negotiation not found: %s", negotiationID),
+			}
+		}
+		return nil, negotiation, &api.ApiError{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		}
+	}
+
+	if claims.Email != negotiation.BuyerEmail && claims.Email != negotiation.SellerEmail {
+		return nil, negotiation, &api.ApiError{
+			Status: http.StatusForbidden,
+			Err:    fmt.Errorf("not a participant in negotiation %s", negotiationID),
+		}
+	}
+
+	return claims, negotiation, nil
+}
@@ -0,0 +1,212 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"log/slog"
+	"time"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	backfillInterval = 10 * time.Minute
+	// maxBackfilled caps the images a single sweep reprocesses.
+	maxBackfilled = 20
+)
+
+// Backfiller brings listing images stored before their width and size were
+// recorded up to date. Images stored before variants are reprocessed like a
+// new upload, so their full rendition stops carrying EXIF and GPS data, and
+// the blobs they replace are released to the storage collector. Newer images
+// only have their renditions measured.
+type Backfiller struct {
+	db    *pgxpool.Pool
+	store storage.Store
+	// after is the image ID the next sweep resumes from, so an image that
+	// keeps failing doesn't hold up the rest.
+	after string
+}
+
+func NewBackfiller(db *pgxpool.Pool, store storage.Store) *Backfiller {
+	return &Backfiller{
+		db:    db,
+		store: store,
+	}
+}
+
+// Start backfills a batch of images every ten minutes until the returned
+// stop func is called. Once every image is measured, sweeps find nothing to
+// do.
+func (b *Backfiller) Start(ctx context.Context) func() {
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		ticker := time.NewTicker(backfillInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.backfill(ctx)
+			}
+		}
+	}()
+
+	return cancel
+}
+
+// backfill works through the next batch of images, wrapping around to the
+// start once it reaches the end.
+func (b *Backfiller) backfill(ctx context.Context) {
+	rows, err := database.New(b.db).UnsizedListingImages(ctx, database.UnsizedListingImagesParams{
+		AfterID:  b.after,
+		MaxCount: maxBackfilled,
+	})
+	if err != nil {
+		slog.Error("failed to fetch images to backfill", "err", err)
+		return
+	}
+
+	if len(rows) < maxBackfilled {
+		b.after = ""
+	} else {
+		b.after = rows[len(rows)-1].ID
+	}
+
+	for _, row := range rows {
+		if err := b.reprocess(ctx, row); err != nil {
+			slog.Error("failed to backfill image", "id", row.ID, "err", err)
+		}
+	}
+
+	if len(rows) > 0 {
+		slog.Info("Backfilled images", "count", len(rows))
+	}
+}
+
+// reprocess records the width and size of one image, first regenerating its
+// variants if it predates them.
+func (b *Backfiller) reprocess(ctx context.Context, row database.ListingImage) error {
+	blob, err := b.store.Get(ctx, row.ImageUrl)
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+
+	params := database.ReplaceListingImageVariantsParams{
+		ImageUrl:     row.ImageUrl,
+		ThumbnailUrl: row.ThumbnailUrl.String,
+		CardUrl:      row.CardUrl.String,
+		ImageID:      row.ID,
+		OldImageUrl:  row.ImageUrl,
+	}
+
+	// Images stored with variants were already stripped when uploaded.
+	if row.ThumbnailUrl.Valid && row.CardUrl.Valid {
+		config, _, err := image.DecodeConfig(blob)
+		if err != nil {
+			return fmt.Errorf("failed to decode image: %w", err)
+		}
+		params.Width = int32(config.Width)
+		params.SizeBytes = blob.Size
+
+		// Only the renditions' sizes are needed, so they aren't read.
+		for _, url := range []string{row.ThumbnailUrl.String, row.CardUrl.String} {
+			rendition, err := b.store.Get(ctx, url)
+			if err != nil {
+				return err
+			}
+			rendition.Close()
+			params.SizeBytes += rendition.Size
+		}
+
+		_, err = database.New(b.db).ReplaceListingImageVariants(ctx, params)
+		return err
+	}
+
+	variants, err := Process(ctx, blob)
+	if err != nil {
+		return err
+	}
+
+	// Stored blobs stay pending until the swap commits, so the collector
+	// removes them if it never does.
+	stored := make([]string, 0, len(variants))
+	for _, v := range variants {
+		url, err := b.put(ctx, fmt.Sprintf("%s_%s.jpg", row.ID, v.Variant.Name), v.Data)
+		if err != nil {
+			return err
+		}
+		stored = append(stored, url)
+		params.SizeBytes += int64(len(v.Data))
+
+		switch v.Variant.Name {
+		case VariantThumbnail:
+			params.ThumbnailUrl = url
+		case VariantCard:
+			params.CardUrl = url
+		case VariantFull:
+			params.ImageUrl = url
+			params.Width = int32(v.Width)
+		}
+	}
+
+	queries, tx, err := database.NewQueries(ctx, b.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	replaced, err := queries.ReplaceListingImageVariants(ctx, params)
+	if err != nil {
+		return err
+	}
+	if replaced == 0 {
+		return nil
+	}
+
+	// Release the original, metadata and all, along with any renditions it
+	// had.
+	released := []string{row.ImageUrl}
+	if row.ThumbnailUrl.Valid {
+		released = append(released, row.ThumbnailUrl.String)
+	}
+	if row.CardUrl.Valid {
+		released = append(released, row.CardUrl.String)
+	}
+	for _, url := range released {
+		if err := queries.RecordPendingUpload(ctx, url); err != nil {
+			return err
+		}
+	}
+
+	if err := queries.CommitPendingUploads(ctx, stored); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// put stores a rendition, tracking it as pending before writing it.
+func (b *Backfiller) put(ctx context.Context, name string, data []byte) (string, error) {
+	url, err := b.store.Reserve(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	if err := database.New(b.db).RecordPendingUpload(ctx, url); err != nil {
+		return "", err
+	}
+
+	if err := b.store.Put(ctx, url, name, data); err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
@@ -0,0 +1,171 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+
+	// Register the formats accepted on upload.
+	_ "image/gif"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	VariantThumbnail = "thumbnail"
+	VariantCard      = "card"
+	VariantFull      = "full"
+)
+
+// Variant is a stored rendition of an uploaded image, no wider than Width.
+type Variant struct {
+	Name  string
+	Width int
+}
+
+// Variants lists the renditions generated for every upload, smallest first.
+// An image narrower than a variant's width keeps its own width.
+var Variants = []Variant{
+	{Name: VariantThumbnail, Width: 320},
+	{Name: VariantCard, Width: 800},
+	{Name: VariantFull, Width: 1920},
+}
+
+const jpegQuality = 85
+
+// maxDecodes bounds how many images the whole process decodes at once. A
+// decoded image at MaxDimension takes up to 256 MB, so this, not the number
+// of requests or their workers, is what bounds the memory spent on images.
+const maxDecodes = 2
+
+var decodeSlots = make(chan struct{}, maxDecodes)
+
+// Processed is a single encoded rendition of an upload. Its stored size is
+// len(Data).
+type Processed struct {
+	Variant Variant
+	// Width is the encoded image's real width in pixels.
+	Width int
+	Data  []byte
+}
+
+// Process decodes an uploaded image, rotates it upright according to its
+// EXIF orientation and re-encodes it as a JPEG at each variant width.
+// Re-encoding discards EXIF, GPS and any other embedded metadata. It waits
+// for one of the process's decode slots, returning early if ctx is done.
+func Process(ctx context.Context, r io.Reader) ([]Processed, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case decodeSlots <- struct{}{}:
+		defer func() { <-decodeSlots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	orientation := exifOrientation(data)
+
+	processed := make([]Processed, 0, len(Variants))
+	for _, v := range Variants {
+		img := orient(scale(src, v.Width, swapsAxes(orientation)), orientation)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, fmt.Errorf("failed to encode %s image: %w", v.Name, err)
+		}
+
+		processed = append(processed, Processed{Variant: v, Width: img.Bounds().Dx(), Data: buf.Bytes()})
+	}
+
+	return processed, nil
+}
+
+// scale shrinks src so that its upright width is at most width, never
+// enlarging it. When swapped, the stored image is rotated a quarter turn, so
+// its height becomes the upright width. Transparent areas are flattened onto
+// white, since JPEG has no alpha channel.
+func scale(src image.Image, width int, swapped bool) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if swapped {
+		w, h = h, w
+	}
+
+	if w > width {
+		h = h * width / w
+		w = width
+	}
+	if h < 1 {
+		h = 1
+	}
+	if swapped {
+		w, h = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+
+	return dst
+}
+
+// swapsAxes reports whether an EXIF orientation involves a quarter turn.
+func swapsAxes(orientation int) bool {
+	return orientation >= 5 && orientation <= 8
+}
+
+// orient applies an EXIF orientation (1-8) so the image displays upright.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	dw, dh := w, h
+	if swapsAxes(orientation) {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirror horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotate 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirror vertically
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // rotate 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotate 90° counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.SetRGBA(dx, dy, src.RGBAAt(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+
+	return dst
+}
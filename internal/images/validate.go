@@ -0,0 +1,78 @@
+package images
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+)
+
+const (
+	// MaxFileSize caps a single uploaded image.
+	MaxFileSize = 10 << 20
+	// MaxListingImages caps how many images one listing may have.
+	MaxListingImages = 10
+	// MaxUploadSize caps the images uploaded in a single request.
+	MaxUploadSize = 40 << 20
+	// MaxListingSize caps the stored renditions of all of a listing's
+	// images, however many requests added them.
+	MaxListingSize = 30 << 20
+
+	MinDimension = 200
+	MaxDimension = 8000
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooLarge        = errors.New("image too large")
+	ErrInvalidImage    = errors.New("invalid image")
+)
+
+// allowedTypes maps the sniffed MIME types accepted on upload to a name fit
+// for error messages.
+var allowedTypes = map[string]string{
+	"image/jpeg": "JPEG",
+	"image/png":  "PNG",
+	"image/gif":  "GIF",
+	"image/webp": "WebP",
+}
+
+// Validate checks an upload by content rather than by its declared type or
+// extension: the sniffed MIME type must be an allowed image format, and the
+// header must decode to dimensions within bounds. Errors wrap one of
+// ErrUnsupportedType, ErrTooLarge or ErrInvalidImage.
+func Validate(r io.ReadSeeker, size int64) error {
+	if size > MaxFileSize {
+		return fmt.Errorf("%w: larger than %d MB", ErrTooLarge, MaxFileSize>>20)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	if _, ok := allowedTypes[contentType]; !ok {
+		return fmt.Errorf("%w: %s is not a JPEG, PNG, GIF or WebP image", ErrUnsupportedType, contentType)
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return fmt.Errorf("%w: could not read %s image: %v", ErrInvalidImage, allowedTypes[contentType], err)
+	}
+
+	if config.Width < MinDimension || config.Height < MinDimension {
+		return fmt.Errorf("%w: %dx%d is smaller than %dx%d", ErrInvalidImage, config.Width, config.Height, MinDimension, MinDimension)
+	}
+	if config.Width > MaxDimension || config.Height > MaxDimension {
+		return fmt.Errorf("%w: %dx%d is larger than %dx%d", ErrTooLarge, config.Width, config.Height, MaxDimension, MaxDimension)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const refreshInterval = 15 * time.Minute
+
+// TermRefresher keeps the search_terms view that did-you-mean suggestions
+// draw from up to date with active listings.
+type TermRefresher struct {
+	db *pgxpool.Pool
+}
+
+func NewTermRefresher(db *pgxpool.Pool) *TermRefresher {
+	return &TermRefresher{
+		db: db,
+	}
+}
+
+// Start refreshes search terms every fifteen minutes until the returned stop
+// func is called.
+func (t *TermRefresher) Start(ctx context.Context) func() {
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := database.New(t.db).RefreshSearchTerms(ctx); err != nil {
+					slog.Error("failed to refresh search terms", "err", err)
+				}
+			}
+		}
+	}()
+
+	return cancel
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
 
@@ -11,30 +12,32 @@ import (
 	"time"
 
 	"github.com/DillonEnge/jolt/database"
+	"github.com/DillonEnge/jolt/internal/alerts"
 	"github.com/DillonEnge/jolt/internal/api"
 	"github.com/DillonEnge/jolt/internal/api/middleware"
 	v1 "github.com/DillonEnge/jolt/internal/api/v1"
 	"github.com/DillonEnge/jolt/internal/auth"
+	"github.com/DillonEnge/jolt/internal/images"
+	"github.com/DillonEnge/jolt/internal/search"
 	"github.com/DillonEnge/jolt/internal/sessions"
-	"github.com/DillonEnge/jolt/templates"
-	"github.com/DillonEnge/seaweedfs-go-client"
-	"github.com/a-h/templ"
+	"github.com/DillonEnge/jolt/internal/storage"
+	"github.com/DillonEnge/jolt/internal/views"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nats-io/nats.go"
 )
 
-func Start(address string, dbPool *pgxpool.Pool, nc *nats.Conn, config *api.Config) func(context.Context) error {
+func Start(address string, dbPool *pgxpool.Pool, nc *nats.Conn, store storage.Store, config *api.Config) func(context.Context) error {
 	sm := sessions.NewSessionManager()
 
 	authClient := auth.NewClient(config)
 
-	fsClient := seaweedfs.NewClient(seaweedfs.Config{
-		MasterURL:  config.SeaweedFS.MasterURL,
-		VolumesURL: config.SeaweedFS.VolumesURL,
-	})
-
 	db := database.New(dbPool)
 
+	// page renders a fragment route as a full page for non-HTMX requests.
+	page := func(h api.HandlerFuncWithError, active string) http.HandlerFunc {
+		return makeH(v1.HandlePage(h, active, sm, authClient, config))
+	}
+
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
@@ -42,22 +45,44 @@ func Start(address string, dbPool *pgxpool.Pool, nc *nats.Conn, config *api.Conf
 		w.Write([]byte("OK"))
 	})
 
-	mux.HandleFunc("GET /navbar", makeH(v1.HandleNavbar(sm, authClient)))
-
-	mux.Handle("GET /search", templ.Handler(templates.Search()))
-
-	mux.HandleFunc("GET /listings/popular", makeH(v1.HandlePopularListings(db, authClient, sm)))
-	mux.HandleFunc("GET /listings", makeH(v1.HandleListings(db, authClient, sm)))
-	mux.HandleFunc("POST /listings", makeH(v1.HandlePostListings(db, fsClient, config)))
-	mux.HandleFunc("DELETE /listings", makeH(v1.HandleDeleteListings(dbPool)))
-	mux.HandleFunc("PATCH /listings", makeH(v1.HandlePatchListing(db)))
-
-	mux.HandleFunc("GET /create-listing", makeH(v1.HandleCreateListing(sm, authClient)))
-
-	mux.Handle("GET /negotiations", makeH(v1.HandleNegotiations(dbPool, authClient, sm)))
+	mux.HandleFunc("GET /navbar", makeH(v1.HandleNavbar(db, sm, authClient)))
+
+	mux.HandleFunc("GET /search", page(v1.HandleSearch(db, sm), "search"))
+
+	mux.HandleFunc("GET /listings/popular", page(v1.HandlePopularListings(db, authClient, sm), "trending"))
+	mux.HandleFunc("GET /listings", page(v1.HandleListings(db, authClient, sm), "search"))
+	mux.HandleFunc("POST /listings", makeH(v1.HandlePostListings(dbPool, store, nc, authClient, sm)))
+	mux.HandleFunc("DELETE /listings", makeH(v1.HandleDeleteListings(dbPool, authClient, sm)))
+	mux.HandleFunc("PATCH /listings", makeH(v1.HandlePatchListing(dbPool, authClient, sm)))
+	mux.HandleFunc("PUT /listings", makeH(v1.HandlePutListing(dbPool, store, authClient, sm)))
+	mux.HandleFunc("GET /listings/{id}", makeH(v1.HandleListingPage(db, authClient, sm, config)))
+	mux.HandleFunc("GET /images/{id}/{variant}", makeH(v1.HandleImage(db, store)))
+	mux.HandleFunc("GET /listings/edit", page(v1.HandleEditListing(dbPool, authClient, sm), "mylistings"))
+	mux.HandleFunc("POST /listings/status", makeH(v1.HandlePostListingStatus(dbPool, nc, authClient, sm)))
+
+	mux.HandleFunc("GET /categories", page(v1.HandleCategories(db, authClient, sm), "categories"))
+	mux.HandleFunc("GET /categories/listings", page(v1.HandleCategoryListings(db, authClient, sm), "categories"))
+	mux.HandleFunc("POST /categories", makeH(v1.HandlePostCategory(dbPool, authClient, sm)))
+	mux.HandleFunc("PUT /categories", makeH(v1.HandlePutCategory(dbPool, authClient, sm)))
+	mux.HandleFunc("DELETE /categories", makeH(v1.HandleDeleteCategory(dbPool, authClient, sm)))
+	mux.HandleFunc("GET /categories/attributes/fields", makeH(v1.HandleCategoryAttributeFields(db)))
+	mux.HandleFunc("GET /categories/attributes/filters", makeH(v1.HandleCategoryAttributeFilters(db)))
+	mux.HandleFunc("POST /categories/attributes", makeH(v1.HandlePostCategoryAttribute(dbPool, authClient, sm)))
+	mux.HandleFunc("DELETE /categories/attributes", makeH(v1.HandleDeleteCategoryAttribute(dbPool, authClient, sm)))
+
+	mux.HandleFunc("GET /create-listing", page(v1.HandleCreateListing(db, sm, authClient), "createlisting"))
+
+	mux.Handle("GET /negotiations", page(v1.HandleNegotiations(dbPool, authClient, sm), "negotiations"))
 	mux.Handle("POST /negotiations", makeH(v1.HandlePostNegotiation(db, authClient, sm)))
+	mux.Handle("POST /negotiations/complete", makeH(v1.HandleCompleteNegotiation(dbPool, authClient, sm)))
+	mux.Handle("POST /negotiations/cancel", makeH(v1.HandleCancelNegotiation(dbPool, authClient, sm)))
 
-	mux.Handle("GET /chat", makeH(v1.HandleChat(dbPool, sm, authClient, config)))
+	mux.Handle("POST /offers", makeH(v1.HandlePostOffer(dbPool, authClient, sm)))
+	mux.Handle("POST /offers/counter", makeH(v1.HandlePostCounterOffer(dbPool, authClient, sm)))
+	mux.Handle("POST /offers/accept", makeH(v1.HandleAcceptOffer(dbPool, authClient, sm)))
+	mux.Handle("POST /offers/reject", makeH(v1.HandleRejectOffer(dbPool, authClient, sm)))
+
+	mux.Handle("GET /chat", page(v1.HandleChat(dbPool, sm, authClient, config), "negotiations"))
 
 	mux.Handle("GET /ws/messages", makeH(v1.HandleMessageWS(db, authClient, nc, sm)))
 	mux.Handle("GET /messages", makeH(v1.HandleMessages(dbPool)))
@@ -72,7 +97,17 @@ func Start(address string, dbPool *pgxpool.Pool, nc *nats.Conn, config *api.Conf
 		),
 	)
 
-	mux.HandleFunc("GET /my-listings", makeH(v1.HandleMyListings(dbPool, authClient, sm)))
+	mux.HandleFunc("GET /my-listings", page(v1.HandleMyListings(dbPool, authClient, sm), "mylistings"))
+
+	mux.HandleFunc("GET /watchlist", page(v1.HandleWatchlist(dbPool, authClient, sm), "saved"))
+	mux.HandleFunc("POST /watchlist", makeH(v1.HandlePostWatchlist(db, authClient, sm)))
+	mux.HandleFunc("DELETE /watchlist", makeH(v1.HandleDeleteWatchlist(db, authClient, sm)))
+
+	mux.HandleFunc("POST /saved-searches", makeH(v1.HandlePostSavedSearch(db, authClient, sm)))
+	mux.HandleFunc("PUT /saved-searches", makeH(v1.HandlePutSavedSearch(db, authClient, sm)))
+	mux.HandleFunc("DELETE /saved-searches", makeH(v1.HandleDeleteSavedSearch(db, authClient, sm)))
+
+	mux.HandleFunc("GET /analytics", page(v1.HandleAnalytics(dbPool, authClient, sm), "analytics"))
 
 	mux.HandleFunc("GET /loader", makeH(v1.HandleLoader()))
 
@@ -106,9 +141,39 @@ func Start(address string, dbPool *pgxpool.Pool, nc *nats.Conn, config *api.Conf
 }
 
 func Service(ctx context.Context, dbPool *pgxpool.Pool, nc *nats.Conn, config *api.Config) (func(), error) {
-	shutdown := Start(fmt.Sprintf(":%d", config.Port), dbPool, nc, config)
+	stopMatcher, err := alerts.NewMatcher(dbPool, nc).Start(ctx)
+	if err != nil {
+		return func() {}, err
+	}
+
+	signingKey := []byte(config.ImageSigningKey)
+	if len(signingKey) == 0 {
+		slog.Warn("IMAGE_SIGNING_KEY is not set; image URLs will stop working on restart")
+		signingKey = make([]byte, 32)
+		rand.Read(signingKey)
+	}
+	images.SetSigningKey(signingKey)
+
+	store, err := storage.New(config)
+	if err != nil {
+		stopMatcher()
+		return func() {}, err
+	}
+
+	stopCollector := storage.NewCollector(dbPool, store).Start(ctx)
+	stopRefresher := search.NewTermRefresher(dbPool).Start(ctx)
+	stopBackfiller := images.NewBackfiller(dbPool, store).Start(ctx)
+	stopPruner := views.NewPruner(dbPool).Start(ctx)
+
+	shutdown := Start(fmt.Sprintf(":%d", config.Port), dbPool, nc, store, config)
 
 	stopService := func() {
+		stopMatcher()
+		stopCollector()
+		stopRefresher()
+		stopBackfiller()
+		stopPruner()
+
 		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
 
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Blob is a stored blob being read.
+type Blob struct {
+	io.ReadSeekCloser
+	// Size is the blob's length in bytes.
+	Size int64
+	// ContentType is the blob's MIME type, or empty if the store doesn't
+	// know it.
+	ContentType string
+}
+
+// openBlob GETs a blob over HTTP. newRequest builds a fresh GET of the blob
+// each time it is called.
+func openBlob(client *http.Client, newRequest func() (*http.Request, error)) (*Blob, error) {
+	req, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status reading blob %s: %s", req.URL.Path, resp.Status)
+	}
+	if resp.ContentLength < 0 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("no content length reading blob %s", req.URL.Path)
+	}
+
+	return &Blob{
+		ReadSeekCloser: &httpBlob{
+			client:     client,
+			newRequest: newRequest,
+			body:       resp.Body,
+			size:       resp.ContentLength,
+		},
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
+
+// httpBlob reads a blob over HTTP. It keeps streaming the response it has
+// open for as long as reads are sequential, and after a seek reopens the
+// blob at the new offset with a Range request.
+type httpBlob struct {
+	client     *http.Client
+	newRequest func() (*http.Request, error)
+	// body is the open response, if any, positioned at bodyOffset.
+	body       io.ReadCloser
+	bodyOffset int64
+	offset     int64
+	size       int64
+}
+
+func (b *httpBlob) Read(p []byte) (int, error) {
+	if b.body != nil && b.bodyOffset != b.offset {
+		b.body.Close()
+		b.body = nil
+	}
+	if b.offset >= b.size {
+		return 0, io.EOF
+	}
+
+	if b.body == nil {
+		if err := b.reopen(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := b.body.Read(p)
+	b.offset += int64(n)
+	b.bodyOffset = b.offset
+
+	return n, err
+}
+
+// reopen requests the blob from the current offset to its end.
+func (b *httpBlob) reopen() error {
+	req, err := b.newRequest()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", b.offset))
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return fmt.Errorf("unexpected status reading blob %s from %d: %s", req.URL.Path, b.offset, resp.Status)
+	}
+
+	b.body = resp.Body
+	b.bodyOffset = b.offset
+
+	return nil
+}
+
+func (b *httpBlob) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.offset
+	case io.SeekEnd:
+		offset += b.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	b.offset = offset
+
+	return offset, nil
+}
+
+func (b *httpBlob) Close() error {
+	if b.body == nil {
+		return nil
+	}
+
+	err := b.body.Close()
+	b.body = nil
+
+	return err
+}
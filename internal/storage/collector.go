@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	collectInterval = 10 * time.Minute
+	// maxCollected caps the blobs a single sweep deletes.
+	maxCollected = 100
+
+	reconcileInterval = 24 * time.Hour
+	// reconcileBatch is how many listed blobs are checked against the
+	// database at once.
+	reconcileBatch = 500
+	// reconcileGrace spares recently written blobs, leaving uploads still in
+	// flight to pending_uploads.
+	reconcileGrace = time.Hour
+)
+
+// Collector deletes stored blobs that no listing references: uploads whose
+// listing was never committed, and images released by deleted listings or
+// edits. Both are tracked in pending_uploads. Stores that can list their
+// blobs are also reconciled against the database, which catches blobs
+// stored before tracking existed.
+type Collector struct {
+	db    *pgxpool.Pool
+	store Store
+}
+
+func NewCollector(db *pgxpool.Pool, store Store) *Collector {
+	return &Collector{
+		db:    db,
+		store: store,
+	}
+}
+
+// Start sweeps orphaned uploads every ten minutes, and reconciles the store
+// on startup and daily after, until the returned stop func is called.
+func (c *Collector) Start(ctx context.Context) func() {
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		ticker := time.NewTicker(collectInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.collect(ctx)
+			}
+		}
+	}()
+
+	if lister, ok := c.store.(Lister); ok {
+		go func() {
+			ticker := time.NewTicker(reconcileInterval)
+			defer ticker.Stop()
+
+			for {
+				c.reconcile(ctx, lister)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+
+	return cancel
+}
+
+// collect deletes each orphaned blob, then stops tracking it. A blob that
+// fails to delete stays tracked and is retried on the next sweep, unless it
+// belongs to another backend, which this store can never delete.
+func (c *Collector) collect(ctx context.Context) {
+	queries := database.New(c.db)
+
+	urls, err := queries.OrphanedUploads(ctx, maxCollected)
+	if err != nil {
+		slog.Error("failed to fetch orphaned uploads", "err", err)
+		return
+	}
+
+	for _, url := range urls {
+		err := c.store.Delete(ctx, url)
+		if errors.Is(err, ErrNotOwned) {
+			slog.Warn("dropping orphaned upload from another storage backend", "url", url)
+		} else if err != nil {
+			slog.Error("failed to delete orphaned upload", "url", url, "err", err)
+			continue
+		}
+
+		if err := queries.DeletePendingUpload(ctx, url); err != nil {
+			slog.Error("failed to clear orphaned upload", "url", url, "err", err)
+		}
+	}
+
+	if len(urls) > 0 {
+		slog.Info("Collected orphaned uploads", "count", len(urls))
+	}
+}
+
+// reconcile deletes every blob in the store, past the grace period, that
+// neither a listing image nor a pending upload refers to.
+func (c *Collector) reconcile(ctx context.Context, lister Lister) {
+	queries := database.New(c.db)
+	cutoff := time.Now().Add(-reconcileGrace)
+
+	batch := make([]string, 0, reconcileBatch)
+	deleted := 0
+	flush := func() error {
+		urls, err := queries.UntrackedBlobs(ctx, batch)
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+
+		for _, url := range urls {
+			if err := c.store.Delete(ctx, url); err != nil {
+				slog.Error("failed to delete untracked blob", "url", url, "err", err)
+				continue
+			}
+			deleted++
+		}
+
+		return nil
+	}
+
+	err := lister.List(ctx, func(url string, modified time.Time) error {
+		if modified.After(cutoff) {
+			return nil
+		}
+
+		batch = append(batch, url)
+		if len(batch) < reconcileBatch {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil && len(batch) > 0 {
+		err = flush()
+	}
+	if err != nil {
+		slog.Error("failed to reconcile stored blobs", "err", err)
+	}
+
+	if deleted > 0 {
+		slog.Info("Deleted untracked blobs", "count", deleted)
+	}
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DillonEnge/jolt/internal/api"
+)
+
+// Local stores blobs as files in a directory, for development and
+// single-box deployments. Blob URLs are config.URL followed by the file name.
+type Local struct {
+	dir string
+	url string
+}
+
+func NewLocal(config api.LocalStorageConfig) (*Local, error) {
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &Local{
+		dir: config.Dir,
+		url: strings.TrimSuffix(config.URL, "/"),
+	}, nil
+}
+
+func (l *Local) Reserve(ctx context.Context, name string) (string, error) {
+	key, err := newKey(name)
+	if err != nil {
+		return "", err
+	}
+
+	return l.url + "/" + key, nil
+}
+
+func (l *Local) Put(ctx context.Context, url string, name string, data []byte) error {
+	path, err := l.path(url)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (l *Local) Get(ctx context.Context, url string) (*Blob, error) {
+	path, err := l.path(url)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &Blob{
+		ReadSeekCloser: f,
+		Size:           info.Size(),
+		ContentType:    mime.TypeByExtension(filepath.Ext(path)),
+	}, nil
+}
+
+func (l *Local) Delete(ctx context.Context, url string) error {
+	path, err := l.path(url)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (l *Local) List(ctx context.Context, fn func(url string, modified time.Time) error) error {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := fn(l.url+"/"+entry.Name(), info.ModTime()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// path maps a blob URL to its file, refusing URLs outside the directory.
+func (l *Local) path(url string) (string, error) {
+	key, ok := strings.CutPrefix(url, l.url+"/")
+	if !ok || !filepath.IsLocal(key) {
+		return "", ErrNotOwned
+	}
+
+	return filepath.Join(l.dir, key), nil
+}
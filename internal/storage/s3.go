@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/DillonEnge/jolt/internal/api"
+)
+
+// S3 stores blobs in a bucket on any S3-compatible service, such as MinIO.
+// Objects are addressed path-style and requests are signed with AWS
+// Signature Version 4.
+type S3 struct {
+	http      *http.Client
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+}
+
+func NewS3(config api.S3Config) (*S3, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("s3 storage requires an endpoint and a bucket")
+	}
+
+	endpoint := strings.TrimSuffix(config.Endpoint, "/")
+
+	region := config.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	publicURL := strings.TrimSuffix(config.PublicURL, "/")
+	if publicURL == "" {
+		publicURL = endpoint + "/" + config.Bucket
+	}
+
+	return &S3{
+		http:      &http.Client{Timeout: 30 * time.Second},
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    config.Bucket,
+		accessKey: config.AccessKeyID,
+		secretKey: config.SecretAccessKey,
+		publicURL: publicURL,
+	}, nil
+}
+
+func (s *S3) Reserve(ctx context.Context, name string) (string, error) {
+	key, err := newKey(name)
+	if err != nil {
+		return "", err
+	}
+
+	return s.publicURL + "/" + key, nil
+}
+
+func (s *S3) Put(ctx context.Context, url string, name string, data []byte) error {
+	key, ok := strings.CutPrefix(url, s.publicURL+"/")
+	if !ok || key == "" {
+		return ErrNotOwned
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, nil, data)
+	if err != nil {
+		return err
+	}
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return s.do(req, http.StatusOK)
+}
+
+func (s *S3) Get(ctx context.Context, url string) (*Blob, error) {
+	key, ok := strings.CutPrefix(url, s.publicURL+"/")
+	if !ok || key == "" {
+		return nil, ErrNotOwned
+	}
+
+	return openBlob(s.http, func() (*http.Request, error) {
+		return s.newRequest(ctx, http.MethodGet, key, nil, nil)
+	})
+}
+
+func (s *S3) Delete(ctx context.Context, url string) error {
+	key, ok := strings.CutPrefix(url, s.publicURL+"/")
+	if !ok || key == "" {
+		return ErrNotOwned
+	}
+
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	// S3 answers 204 whether or not the object existed.
+	return s.do(req, http.StatusNoContent, http.StatusNotFound)
+}
+
+// listBucketResult is the part of a ListObjectsV2 response List reads.
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through the bucket with ListObjectsV2. It assumes the bucket
+// holds only Jolt's blobs.
+func (s *S3) List(ctx context.Context, fn func(url string, modified time.Time) error) error {
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		req, err := s.newRequest(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.http.Do(req)
+		if err != nil {
+			return err
+		}
+
+		var result listBucketResult
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("unexpected status from s3 %s %s: %s", req.Method, req.URL.Path, resp.Status)
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, object := range result.Contents {
+			if err := fn(s.publicURL+"/"+object.Key, object.LastModified); err != nil {
+				return err
+			}
+		}
+
+		if !result.IsTruncated {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *S3) newRequest(ctx context.Context, method, key string, query url.Values, data []byte) (*http.Request, error) {
+	u, err := url.Parse(fmt.Sprintf("%s/%s/%s", s.endpoint, s.bucket, key))
+	if err != nil {
+		return nil, err
+	}
+	// Encode sorts the keys, as the canonical request requires.
+	u.RawQuery = strings.ReplaceAll(query.Encode(), "+", "%20")
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, data, time.Now().UTC())
+
+	return req, nil
+}
+
+func (s *S3) do(req *http.Request, okStatuses ...int) error {
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	for _, status := range okStatuses {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	return fmt.Errorf("unexpected status from s3 %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, body)
+}
+
+// sign adds AWS Signature Version 4 headers for the request.
+func (s *S3) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DillonEnge/jolt/internal/api"
+)
+
+// SeaweedFS stores blobs on a SeaweedFS cluster, serving them straight from
+// its volume servers. It speaks the master's and volume servers' HTTP APIs
+// directly, so every request honours its context and a timeout.
+type SeaweedFS struct {
+	http       *http.Client
+	masterURL  string
+	volumesURL string
+}
+
+func NewSeaweedFS(config api.SeaweedFSConfig) *SeaweedFS {
+	return &SeaweedFS{
+		http:       &http.Client{Timeout: 30 * time.Second},
+		masterURL:  strings.TrimSuffix(config.MasterURL, "/"),
+		volumesURL: config.VolumesURL,
+	}
+}
+
+// Reserve has the master assign a file ID, which fixes the blob's URL.
+func (s *SeaweedFS) Reserve(ctx context.Context, name string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.masterURL+"/dir/assign", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var assigned struct {
+		FID   string `json:"fid"`
+		Error string `json:"error"`
+	}
+	if err := s.doJSON(req, &assigned); err != nil {
+		return "", fmt.Errorf("failed to assign a file id: %v", err)
+	}
+	if assigned.FID == "" {
+		return "", fmt.Errorf("failed to assign a file id: %s", assigned.Error)
+	}
+
+	return fmt.Sprintf("%s/%s", s.volumesURL, assigned.FID), nil
+}
+
+func (s *SeaweedFS) Put(ctx context.Context, url string, name string, data []byte) error {
+	if !strings.HasPrefix(url, s.volumesURL+"/") {
+		return ErrNotOwned
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(data); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	var uploaded struct {
+		Size  int    `json:"size"`
+		Error string `json:"error"`
+	}
+	if err := s.doJSON(req, &uploaded); err != nil {
+		return fmt.Errorf("failed to upload file: %v", err)
+	}
+	if uploaded.Size == 0 {
+		return fmt.Errorf("failed to upload file: %s", cmp.Or(uploaded.Error, "image size is 0"))
+	}
+
+	return nil
+}
+
+func (s *SeaweedFS) Get(ctx context.Context, url string) (*Blob, error) {
+	if !strings.HasPrefix(url, s.volumesURL+"/") {
+		return nil, ErrNotOwned
+	}
+
+	return openBlob(s.http, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	})
+}
+
+// Delete removes a blob from its volume server.
+func (s *SeaweedFS) Delete(ctx context.Context, url string) error {
+	if !strings.HasPrefix(url, s.volumesURL+"/") {
+		return ErrNotOwned
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status deleting blob: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// doJSON sends req and decodes its JSON response into v. SeaweedFS reports
+// some failures in the body, so v is decoded whatever the status.
+func (s *SeaweedFS) doJSON(req *http.Request, v any) error {
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/DillonEnge/jolt/internal/api"
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	BackendSeaweedFS = "seaweedfs"
+	BackendLocal     = "local"
+	BackendS3        = "s3"
+)
+
+// ErrNotFound is returned when reading a blob that doesn't exist.
+var ErrNotFound = errors.New("blob not found")
+
+// ErrNotOwned is returned when reading or deleting a URL that the store
+// didn't issue, such as a blob written by a previously configured backend.
+var ErrNotOwned = errors.New("blob does not belong to this store")
+
+// Store persists image blobs and serves them from public URLs.
+type Store interface {
+	// Reserve picks the URL a new blob will be served from without storing
+	// anything, so the blob can be tracked before it exists. name is a hint
+	// for the blob's file name and type.
+	Reserve(ctx context.Context, name string) (string, error)
+	// Put stores data at a URL returned by Reserve, with the same name.
+	Put(ctx context.Context, url string, name string, data []byte) error
+	// Get opens the blob served from url for reading. The caller must close
+	// it. Seeking doesn't read the skipped bytes, so serving a range of a
+	// blob only fetches that range.
+	Get(ctx context.Context, url string) (*Blob, error)
+	// Delete removes the blob served from url. Deleting a blob that is
+	// already gone is not an error.
+	Delete(ctx context.Context, url string) error
+}
+
+// Lister is implemented by stores that can enumerate their blobs, which lets
+// the collector find blobs that were never tracked. SeaweedFS can't without a
+// filer, so its blobs are only collected through pending_uploads.
+type Lister interface {
+	// List calls fn with the URL of every stored blob and when it was
+	// written, stopping at the first error fn returns.
+	List(ctx context.Context, fn func(url string, modified time.Time) error) error
+}
+
+// New returns the backend selected by config.Storage.Backend, defaulting to
+// SeaweedFS.
+func New(config *api.Config) (Store, error) {
+	switch config.Storage.Backend {
+	case "", BackendSeaweedFS:
+		return NewSeaweedFS(config.SeaweedFS), nil
+	case BackendLocal:
+		return NewLocal(config.Storage.Local)
+	case BackendS3:
+		return NewS3(config.Storage.S3)
+	}
+
+	return nil, fmt.Errorf("unknown storage backend: %s", config.Storage.Backend)
+}
+
+// newKey returns a unique object key that keeps name's extension, so
+// backends that infer content types from keys serve blobs correctly.
+func newKey(name string) (string, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+
+	return id.String() + path.Ext(name), nil
+}
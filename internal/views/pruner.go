@@ -0,0 +1,80 @@
+package views
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/DillonEnge/jolt/database"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RetentionDays is how many days of view events are kept. It covers the
+// longest window the seller analytics report on; view deduplication only
+// needs the last few minutes.
+const RetentionDays = 365
+
+const (
+	pruneInterval = time.Hour
+	// pruneBatch caps the events a single delete removes, so pruning a large
+	// backlog doesn't hold locks for long.
+	pruneBatch = 10000
+)
+
+// Pruner deletes listing view events once they fall out of the retained
+// window, keeping the event log and its dedupe index bounded.
+type Pruner struct {
+	db *pgxpool.Pool
+}
+
+func NewPruner(db *pgxpool.Pool) *Pruner {
+	return &Pruner{
+		db: db,
+	}
+}
+
+// Start prunes view events every hour until the returned stop func is
+// called.
+func (p *Pruner) Start(ctx context.Context) func() {
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.prune(ctx)
+			}
+		}
+	}()
+
+	return cancel
+}
+
+// prune deletes expired events a batch at a time until none are left.
+func (p *Pruner) prune(ctx context.Context) {
+	var total int64
+	for {
+		deleted, err := database.New(p.db).PruneListingViewEvents(ctx, database.PruneListingViewEventsParams{
+			RetentionDays: RetentionDays,
+			MaxCount:      pruneBatch,
+		})
+		if err != nil {
+			slog.Error("failed to prune listing view events", "err", err)
+			return
+		}
+
+		total += deleted
+		if deleted < pruneBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		slog.Info("Pruned listing view events", "count", total)
+	}
+}
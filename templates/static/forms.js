@@ -0,0 +1,22 @@
+// Shows the error from a failed HTMX request in the form that sent it.
+// Handlers respond with {"error": "..."}; anything else is shown as-is.
+function showFormError(form, event) {
+  const alert = form.querySelector('.form-error');
+  if (!alert) return;
+
+  let message = event.detail.xhr.responseText;
+  try {
+    message = JSON.parse(message).error || message;
+  } catch (_) {}
+
+  alert.textContent = message;
+  alert.classList.remove('hidden');
+}
+
+function clearFormError(form) {
+  const alert = form.querySelector('.form-error');
+  if (!alert) return;
+
+  alert.textContent = '';
+  alert.classList.add('hidden');
+}
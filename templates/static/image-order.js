@@ -0,0 +1,47 @@
+// Lets sellers drag a listing's photos into order on the edit form. Each
+// photo carries a hidden image_order input, so the form submits the order as
+// arranged. The first photo is the cover.
+let draggedImage = null;
+
+function imageItem(target) {
+  return target instanceof Element ? target.closest('[data-image-id]') : null;
+}
+
+document.addEventListener('dragstart', (event) => {
+  draggedImage = imageItem(event.target);
+  if (draggedImage) {
+    event.dataTransfer.effectAllowed = 'move';
+  }
+});
+
+document.addEventListener('dragover', (event) => {
+  const target = imageItem(event.target);
+  if (!draggedImage || !target || target === draggedImage || target.parentElement !== draggedImage.parentElement) return;
+
+  event.preventDefault();
+  const rect = target.getBoundingClientRect();
+  const after = event.clientX > rect.left + rect.width / 2;
+  target.parentElement.insertBefore(draggedImage, after ? target.nextSibling : target);
+  markCover(target.parentElement);
+});
+
+document.addEventListener('drop', (event) => {
+  if (draggedImage) event.preventDefault();
+});
+
+document.addEventListener('dragend', () => {
+  draggedImage = null;
+});
+
+function makeCover(button) {
+  const item = imageItem(button);
+  item.parentElement.prepend(item);
+  markCover(item.parentElement);
+}
+
+function markCover(list) {
+  list.querySelectorAll('[data-image-id]').forEach((item, i) => {
+    item.querySelector('.cover-badge').classList.toggle('hidden', i !== 0);
+    item.querySelector('.make-cover').classList.toggle('hidden', i === 0);
+  });
+}